@@ -0,0 +1,47 @@
+// In this lesson, we move the access control decisions out of the code
+// and into a policy document (attribute based access control, ABAC)
+
+// In the previous lesson, every function checked the attributes of the caller with
+// its own if statement. That is easy to get wrong, and hard to review: the check of
+// UploadDocument was `role != "admin" && department != "IT"`, which only denies the
+// callers that are neither admin nor in IT. Any admin of any department, and anybody
+// in IT, could upload documents.
+
+// With the abac package, each transaction function maps to one expression over the
+// attributes of the caller's certificate (attr.<name>), its MSP ID (mspid) and its
+// organizational units (ou), for example
+//
+//	attr.role == 'admin' && attr.department == 'IT'
+//	mspid in ['Org1MSP', 'Org2MSP'] || ou == 'auditor'
+//
+// The expressions are written in policy.json, which is embedded in the chaincode binary
+// so that every peer evaluates the same policy.
+
+// The evaluator denies by default: a function that has no policy in the document
+// can't be called by anybody. And a denial explains itself, for example
+//
+//	access to UploadDocument denied to client eDUwOTo6... of Org1MSP: attr.department is 'HR', not 'IT'
+//	(policy: attr.role == 'admin' && attr.department == 'IT')
+
+package accesscontrol
+
+import (
+	_ "embed"
+
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/abac"
+)
+
+//go:embed policy.json
+var policyDocument []byte
+
+// documentPolicy is the policy of the DocumentChaincodes. It is loaded when the chaincode
+// starts, so a mistake in policy.json stops the chaincode instead of opening a function.
+var documentPolicy = mustLoadPolicy(policyDocument)
+
+func mustLoadPolicy(document []byte) *abac.Policy {
+	policy, err := abac.Load(document)
+	if err != nil {
+		panic(err)
+	}
+	return policy
+}
@@ -7,111 +7,124 @@
 
 // This lesson is just for demonstration purpose about how Cid works internally
 
+// Package accesscontrol contains the DocumentChaincode that grants access
+// based on the identity of the caller.
+package accesscontrol
+
 import (
-    "encoding/pem"
-    "encoding/x509"
-    "errors"
-    "fmt"
-    "time"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/msp"
 )
 
+// MyContract is the contract used to show how the creator certificate is decoded
 type MyContract struct {
 	contractapi.Contract
 }
 
-
 // DecodeCreator decodes the byte array representation of the creator into an x509 certificate.
-func DecodeCreator(c *MyContract) (*x509.Certificate, error) {
+func DecodeCreator(ctx contractapi.TransactionContextInterface) (*x509.Certificate, error) {
 
-    creatorBytes, err := ctx.GetClientIdentity().GetCreator()
-    // the above variable will look something like this:
-    // creatorBytes = []byte("-----BEGIN CERTIFICATE-----\nMIIBIjCB...-----END CERTIFICATE-----\n")
+	creatorBytes, err := ctx.GetStub().GetCreator()
+	// the above variable is a serialized msp.SerializedIdentity (protobuf) that holds
+	// the MSP ID and the certificate, which will look something like this:
+	// IdBytes = []byte("-----BEGIN CERTIFICATE-----\nMIIBIjCB...-----END CERTIFICATE-----\n")
 	if err != nil {
-		return fmt.Errorf("failed to get creator bytes: %w", err)
+		return nil, fmt.Errorf("failed to get creator bytes: %w", err)
 	}
 
-    // Decode the PEM-encoded certificate
-    block, _ := pem.Decode(creatorBytes)
-    if block == nil {
-        return nil, errors.New("failed to decode PEM block")
-    }
+	identity := &msp.SerializedIdentity{}
+	err = proto.Unmarshal(creatorBytes, identity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal creator: %w", err)
+	}
 
-    // Parse the certificate
-    cert, err := x509.ParseCertificate(block.Bytes)
-    if err != nil {
-        return nil, err
-    }
+	// Decode the PEM-encoded certificate
+	block, _ := pem.Decode(identity.IdBytes)
+	if block == nil {
+		return nil, errors.New("failed to decode PEM block")
+	}
 
-    return cert, nil
+	// Parse the certificate
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return cert, nil
 }
 
 // ExtractPublicKey extracts the public key from the certificate.
 func ExtractPublicKey(cert *x509.Certificate) interface{} {
-    return cert.PublicKey
+	return cert.PublicKey
 }
 
 // ExtractCertificateAttributes extracts the attributes from the certificate.
 func ExtractCertificateAttributes(cert *x509.Certificate) map[string]string {
-    attributes := make(map[string]string)
-    for _, attr := range cert.Subject.Organization {
-        attributes["Organization"] = attr
-    }
-    // Add more attribute fields as needed
-    return attributes
+	attributes := make(map[string]string)
+	for _, attr := range cert.Subject.Organization {
+		attributes["Organization"] = attr
+	}
+	// Add more attribute fields as needed
+	return attributes
 }
 
 // ExtractIssuerInformation extracts information about the issuer from the certificate.
 func ExtractIssuerInformation(cert *x509.Certificate) string {
-    return cert.Issuer.String()
+	return cert.Issuer.String()
 }
 
 // ExtractValidityPeriod extracts the validity period (start and end dates) from the certificate.
 func ExtractValidityPeriod(cert *x509.Certificate) (time.Time, time.Time) {
-    return cert.NotBefore, cert.NotAfter
+	return cert.NotBefore, cert.NotAfter
 }
 
 // ExtractSignature extracts the digital signature from the certificate.
 func ExtractSignature(cert *x509.Certificate) []byte {
-    return cert.Signature
+	return cert.Signature
 }
 
-func main() {
-
-
-    // Decode the creator
-    cert, err := DecodeCreator()
-    if err != nil {
-        fmt.Println("Error decoding creator:", err)
-        return
-    }
+// PrintCreatorInfo prints everything we can read from the certificate of the caller
+func (c *MyContract) PrintCreatorInfo(ctx contractapi.TransactionContextInterface) error {
 
+	// Decode the creator
+	cert, err := DecodeCreator(ctx)
+	if err != nil {
+		return fmt.Errorf("error decoding creator: %v", err)
+	}
 
-    // Certificate got from the DecodeCreator() can have these properties
-    // Print the output (decoded certificate)
-    fmt.Println("Output (decoded certificate):")
-    fmt.Printf("Subject: %s\n", cert.Subject)
-    fmt.Printf("Issuer: %s\n", cert.Issuer)
-    fmt.Printf("Serial Number: %s\n", cert.SerialNumber)
-    fmt.Printf("Not Before: %s\n", cert.NotBefore)
-    fmt.Printf("Not After: %s\n", cert.NotAfter)
-    // Add more fields as needed
+	// Certificate got from the DecodeCreator() can have these properties
+	// Print the output (decoded certificate)
+	fmt.Println("Output (decoded certificate):")
+	fmt.Printf("Subject: %s\n", cert.Subject)
+	fmt.Printf("Issuer: %s\n", cert.Issuer)
+	fmt.Printf("Serial Number: %s\n", cert.SerialNumber)
+	fmt.Printf("Not Before: %s\n", cert.NotBefore)
+	fmt.Printf("Not After: %s\n", cert.NotAfter)
+	// Add more fields as needed
 
+	// Extract and print each type of information
+	publicKey := ExtractPublicKey(cert)
+	fmt.Println("Public Key:", publicKey)
 
-    
-    // Extract and print each type of information
-    publicKey := ExtractPublicKey(cert)
-    fmt.Println("Public Key:", publicKey)
+	attributes := ExtractCertificateAttributes(cert)
+	fmt.Println("Certificate Attributes:", attributes)
 
-    attributes := ExtractCertificateAttributes(cert)
-    fmt.Println("Certificate Attributes:", attributes)
+	issuerInfo := ExtractIssuerInformation(cert)
+	fmt.Println("Issuer Information:", issuerInfo)
 
-    issuerInfo := ExtractIssuerInformation(cert)
-    fmt.Println("Issuer Information:", issuerInfo)
+	validityStart, validityEnd := ExtractValidityPeriod(cert)
+	fmt.Println("Validity Start:", validityStart)
+	fmt.Println("Validity End:", validityEnd)
 
-    validityStart, validityEnd := ExtractValidityPeriod(cert)
-    fmt.Println("Validity Start:", validityStart)
-    fmt.Println("Validity End:", validityEnd)
+	signature := ExtractSignature(cert)
+	fmt.Println("Signature:", signature)
 
-    signature := ExtractSignature(cert)
-    fmt.Println("Signature:", signature)
+	return nil
 }
@@ -0,0 +1,217 @@
+// In this lesson, the DocumentChaincodes store what is uploaded
+
+// The ledger is not the place for the documents themselves: every peer would keep a copy
+// of every byte forever, in the blocks and in the world state. What the ledger keeps is the
+// metadata of every document (name, size, MIME type, who uploaded it and when) and its
+// SHA-256 hash, so that anybody holding a copy of the document can prove it is the one
+// that was uploaded, while the document lives in a file store or a database.
+
+// The uploader sends the content in the transient data of the proposal and its hash as an argument:
+//
+//	peer chaincode invoke ... -c '{"Args":["UploadDocument","report.pdf","application/pdf","<sha256 of report.pdf>"]}' \
+//	    --transient "{\"content\":\"$(base64 -w0 report.pdf)\"}"
+//
+// The transient data is seen by the endorsing peers but is not written in the transaction,
+// so the chaincode hashes the content itself and rejects the upload when the hash does not
+// match. That catches a truncated or corrupted upload, and a client announcing a hash for a
+// document it does not have. Every endorsing peer holds the whole content in memory to hash it,
+// so a content bigger than MaxDocumentSize is rejected before it is hashed.
+
+// Uploading a name again creates a new version of the document. Every version is kept
+// under its own key, and the latest one under the name of the document:
+//
+//	document~report.pdf          -> the latest version
+//	documentVersion~report.pdf~1 -> version 1
+//	documentVersion~report.pdf~2 -> version 2
+
+package accesscontrol
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/canonicaljson"
+)
+
+// Keys of the documents
+const (
+	DocumentKey        = "document"
+	DocumentVersionKey = "documentVersion"
+)
+
+// ContentTransientKey is the transient data key of the content of an uploaded document
+const ContentTransientKey = "content"
+
+// MaxDocumentSize is the largest content UploadDocument accepts, in bytes
+const MaxDocumentSize = 5 * 1024 * 1024
+
+// Document is the metadata of one version of an uploaded document
+type Document struct {
+	Name    string `json:"name"`
+	Version int    `json:"version"`
+	// SHA256 is the hex SHA-256 hash of the content
+	SHA256   string `json:"sha256"`
+	Size     int    `json:"size"`
+	MIMEType string `json:"mimeType"`
+
+	UploaderID    string `json:"uploaderId"`
+	UploaderMSPID string `json:"uploaderMspId"`
+	// UploadedAt is the RFC 3339 time of the upload transaction
+	UploadedAt string `json:"uploadedAt"`
+	TxID       string `json:"txId"`
+}
+
+// uploadDocument stores the content of the transient data as a new version of the document name
+func uploadDocument(stub shim.ChaincodeStubInterface, client cid.ClientIdentity, name string, mimeType string, hash string) (*Document, error) {
+	if name == "" {
+		return nil, fmt.Errorf("the document name is required")
+	}
+	if _, _, err := mime.ParseMediaType(mimeType); err != nil {
+		return nil, fmt.Errorf("invalid MIME type %q: %v", mimeType, err)
+	}
+	hash = strings.ToLower(hash)
+	if decoded, err := hex.DecodeString(hash); err != nil || len(decoded) != sha256.Size {
+		return nil, fmt.Errorf("invalid SHA-256 hash %q, expecting 64 hex digits", hash)
+	}
+
+	transient, err := stub.GetTransient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transient data: %v", err)
+	}
+	content, ok := transient[ContentTransientKey]
+	if !ok {
+		return nil, fmt.Errorf("the content of the document must be in the transient data under %q", ContentTransientKey)
+	}
+	if len(content) > MaxDocumentSize {
+		return nil, fmt.Errorf("the content of document %s is %d bytes, bigger than the maximum of %d bytes", name, len(content), MaxDocumentSize)
+	}
+	sum := sha256.Sum256(content)
+	if actual := hex.EncodeToString(sum[:]); actual != hash {
+		return nil, fmt.Errorf("hash mismatch for document %s: the content hashes to %s, not %s", name, actual, hash)
+	}
+
+	// the new version follows the latest one, if the document was uploaded before
+	version := 1
+	latest, err := readDocument(stub, name, 0)
+	if err == nil {
+		version = latest.Version + 1
+	} else if _, notFound := err.(*documentNotFoundError); !notFound {
+		return nil, err
+	}
+
+	uploaderID, err := client.GetID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user ID: %v", err)
+	}
+	uploaderMSPID, err := client.GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user MSP ID: %v", err)
+	}
+	// the time of the transaction is the same on every endorsing peer, unlike time.Now()
+	txTimestamp, err := stub.GetTxTimestamp()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+
+	document := &Document{
+		Name:          name,
+		Version:       version,
+		SHA256:        hash,
+		Size:          len(content),
+		MIMEType:      mimeType,
+		UploaderID:    uploaderID,
+		UploaderMSPID: uploaderMSPID,
+		UploadedAt:    txTimestamp.AsTime().UTC().Format(time.RFC3339Nano),
+		TxID:          stub.GetTxID(),
+	}
+	// canonical json, like the assets, so every endorsing peer writes the same bytes
+	documentJSON, err := canonicaljson.Marshal(document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal document %s: %v", name, err)
+	}
+
+	latestKey, err := stub.CreateCompositeKey(DocumentKey, []string{name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key of document %s: %v", name, err)
+	}
+	versionKey, err := stub.CreateCompositeKey(DocumentVersionKey, []string{name, strconv.Itoa(version)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key of document %s: %v", name, err)
+	}
+	if err := stub.PutState(latestKey, documentJSON); err != nil {
+		return nil, fmt.Errorf("failed to put document %s to world state: %v", name, err)
+	}
+	if err := stub.PutState(versionKey, documentJSON); err != nil {
+		return nil, fmt.Errorf("failed to put document %s to world state: %v", name, err)
+	}
+	return document, nil
+}
+
+// documentNotFoundError tells uploadDocument that a name is uploaded for the first time
+type documentNotFoundError struct {
+	name    string
+	version int
+}
+
+func (e *documentNotFoundError) Error() string {
+	if e.version == 0 {
+		return fmt.Sprintf("the document %s does not exist", e.name)
+	}
+	return fmt.Sprintf("the version %d of the document %s does not exist", e.version, e.name)
+}
+
+// readDocument reads a version of the document name, the latest one if version is 0
+func readDocument(stub shim.ChaincodeStubInterface, name string, version int) (*Document, error) {
+	key, err := stub.CreateCompositeKey(DocumentKey, []string{name})
+	if version != 0 {
+		key, err = stub.CreateCompositeKey(DocumentVersionKey, []string{name, strconv.Itoa(version)})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key of document %s: %v", name, err)
+	}
+
+	documentJSON, err := stub.GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read document %s from world state: %v", name, err)
+	}
+	if documentJSON == nil {
+		return nil, &documentNotFoundError{name: name, version: version}
+	}
+	var document Document
+	if err := json.Unmarshal(documentJSON, &document); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal document %s: %v", name, err)
+	}
+	return &document, nil
+}
+
+// listDocuments returns the latest version of every document, by name
+func listDocuments(stub shim.ChaincodeStubInterface) ([]*Document, error) {
+	resultsIterator, err := stub.GetStateByPartialCompositeKey(DocumentKey, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	documents := []*Document{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list documents: %v", err)
+		}
+		var document Document
+		if err := json.Unmarshal(queryResponse.Value, &document); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal document: %v", err)
+		}
+		documents = append(documents, &document)
+	}
+	return documents, nil
+}
@@ -50,139 +50,199 @@
 
 
 
-package main
+package accesscontrol
 
 import (
-	"errors"
+	"encoding/json"
 	"fmt"
+	"strconv"
 
-	"github.com/hyperledger/fabric-protos-go/peer"
 	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
 	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/peer"
 )
 
-type DocumentChaincode struct{}
+// LowLevelDocumentChaincode is the DocumentChaincode written with the low level shim api
+type LowLevelDocumentChaincode struct{}
 
-func (cc *DocumentChaincode) UploadDocument(stub shim.ChaincodeStubInterface, docName string) peer.Response {
+// Init does nothing, there is no state to set up
+func (cc *LowLevelDocumentChaincode) Init(stub shim.ChaincodeStubInterface) peer.Response {
+	return shim.Success(nil)
+}
 
-	// Get the user's department attribute
-	departmentBytes, err := cid.GetAttributeValue(stub, "department") // ****
-	if err != nil {
-		return shim.Error("failed to get user's department")
+// Invoke routes the transaction to UploadDocument, GetDocument, GetDocumentVersion or ListDocuments
+func (cc *LowLevelDocumentChaincode) Invoke(stub shim.ChaincodeStubInterface) peer.Response {
+	function, args := stub.GetFunctionAndParameters()
+
+	if function == "UploadDocument" {
+		if len(args) != 3 {
+			return shim.Error("Incorrect number of arguments. Expecting 3")
+		}
+		return cc.UploadDocument(stub, args[0], args[1], args[2])
+	} else if function == "GetDocument" {
+		if len(args) != 1 {
+			return shim.Error("Incorrect number of arguments. Expecting 1")
+		}
+		return cc.GetDocument(stub, args[0])
+	} else if function == "GetDocumentVersion" {
+		if len(args) != 2 {
+			return shim.Error("Incorrect number of arguments. Expecting 2")
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil || version < 1 {
+			return shim.Error(fmt.Sprintf("invalid version %q, expecting a number from 1", args[1]))
+		}
+		return cc.GetDocumentVersion(stub, args[0], version)
+	} else if function == "ListDocuments" {
+		if len(args) != 0 {
+			return shim.Error("Incorrect number of arguments. Expecting 0")
+		}
+		return cc.ListDocuments(stub)
 	}
-	department := string(departmentBytes)
 
+	return shim.Error("Invalid Smart Contract function name.")
+}
 
-	// Get the user's role attribute
-	roleBytes, err := cid.GetAttributeValue(stub, "role")
+// authorize checks the policy of function for the user, see abacPolicy_3.go
+func (cc *LowLevelDocumentChaincode) authorize(stub shim.ChaincodeStubInterface, function string) (cid.ClientIdentity, error) {
+	client, err := cid.New(stub)
 	if err != nil {
-		return shim.Error("failed to get user's role")
+		return nil, fmt.Errorf("failed to get user's identity: %v", err)
 	}
-	role := string(roleBytes)
-
+	return client, documentPolicy.Authorize(function, client)
+}
 
-	// Check if the user is authorized to upload documents
-	if role != "admin" && department != "IT" {
-		return shim.Error("only IT admins can upload documents")
-	}
+// UploadDocument stores the metadata of a new version of a document if the caller is allowed to,
+// the content is in the transient data (see documents_4.go)
+func (cc *LowLevelDocumentChaincode) UploadDocument(stub shim.ChaincodeStubInterface, docName string, mimeType string, hash string) peer.Response {
 
-	// Get user ID and MSP ID
-	userIDBytes, err := cid.getID()
+	// Get the identity of the user, with its attributes, MSP ID and OUs,
+	// and check if the user is authorized to upload documents
+	// The rule is in policy.json, see abacPolicy_3.go
+	client, err := cc.authorize(stub, "UploadDocument") // ****
 	if err != nil {
 		return shim.Error(err.Error())
 	}
-	userID := string(userIDBytes)
 
-	userMSPID, err := cid.GetMSPID()
+	// Store the document, with the user ID and MSP ID of the uploader
+	document, err := uploadDocument(stub, client, docName, mimeType, hash)
 	if err != nil {
 		return shim.Error(err.Error())
 	}
 
 
 	// Log user ID and MSP ID
-	fmt.Printf("User %s from MSP %s uploaded document '%s'\n", userID, userMSPID, docName)
+	fmt.Printf("User %s from MSP %s uploaded version %d of document '%s'\n", document.UploaderID, document.UploaderMSPID, document.Version, docName)
 
-	// Proceed with document upload logic...
-	fmt.Printf("Document '%s' uploaded successfully\n", docName)
-	return shim.Success([]byte("Document uploaded successfully"))
+	documentJSON, err := json.Marshal(document)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("failed to marshal document %s: %v", docName, err))
+	}
+	return shim.Success(documentJSON)
 
 
 	// to read all the functions of cid -
 	// https://pkg.go.dev/github.com/hyperledger/fabric-chaincode-go/pkg/cid#ClientIdentity
 }
 
+// GetDocument returns the latest version of a document
+func (cc *LowLevelDocumentChaincode) GetDocument(stub shim.ChaincodeStubInterface, docName string) peer.Response {
+	return cc.GetDocumentVersion(stub, docName, 0)
+}
+
+// GetDocumentVersion returns a version of a document, the latest one if version is 0
+func (cc *LowLevelDocumentChaincode) GetDocumentVersion(stub shim.ChaincodeStubInterface, docName string, version int) peer.Response {
+	function := "GetDocumentVersion"
+	if version == 0 {
+		function = "GetDocument"
+	}
+	if _, err := cc.authorize(stub, function); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	document, err := readDocument(stub, docName, version)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	documentJSON, err := json.Marshal(document)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("failed to marshal document %s: %v", docName, err))
+	}
+	return shim.Success(documentJSON)
+}
+
+// ListDocuments returns the latest version of every document
+func (cc *LowLevelDocumentChaincode) ListDocuments(stub shim.ChaincodeStubInterface) peer.Response {
+	if _, err := cc.authorize(stub, "ListDocuments"); err != nil {
+		return shim.Error(err.Error())
+	}
 
-func main() {
-	err := shim.Start(new(DocumentChaincode))
+	documents, err := listDocuments(stub)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	documentsJSON, err := json.Marshal(documents)
 	if err != nil {
-		fmt.Printf("Error starting chaincode: %s", err)
+		return shim.Error(fmt.Sprintf("failed to marshal documents: %v", err))
 	}
+	return shim.Success(documentsJSON)
 }
 
 
 
 // USING HIGH LEVEL APIs
 // Here the high level api internally calls the cid library
-package main
-
-import (
-	"fmt"
-
-	"github.com/hyperledger/fabric-contract-api-go/contractapi"
-)
 
+// DocumentChaincode is the DocumentChaincode written with the high level contractapi
 type DocumentChaincode struct {
 	contractapi.Contract
 }
 
-func (cc *DocumentChaincode) UploadDocument(ctx contractapi.TransactionContextInterface, docName string) error {
-	// Get the user's department attribute
-	department, err := ctx.GetClientIdentity().GetAttributeValue("department")
-	if err != nil {
-		return fmt.Errorf("failed to get user's department: %v", err)
-	}
-
-	// Get the user's role attribute
-	role, err := ctx.GetClientIdentity().GetAttributeValue("role")
-	if err != nil {
-		return fmt.Errorf("failed to get user's role: %v", err)
-	}
-
+// UploadDocument stores the metadata of a new version of a document if the caller is allowed to,
+// the content is in the transient data (see documents_4.go)
+func (cc *DocumentChaincode) UploadDocument(ctx contractapi.TransactionContextInterface, docName string, mimeType string, hash string) (*Document, error) {
 	// Check if the user is authorized to upload documents
-	if role != "admin" && department != "IT" {
-		return errors.New("only IT admins can upload documents")
+	// The rule is in policy.json, see abacPolicy_3.go
+	if err := documentPolicy.Authorize("UploadDocument", ctx.GetClientIdentity()); err != nil {
+		return nil, err
 	}
 
-	// Get user ID and MSP ID
-	userID, err := ctx.GetClientIdentity().GetID()
+	// Store the document, with the user ID and MSP ID of the uploader
+	document, err := uploadDocument(ctx.GetStub(), ctx.GetClientIdentity(), docName, mimeType, hash)
 	if err != nil {
-		return fmt.Errorf("failed to get user ID: %v", err)
-	}
-
-	userMSPID, err := ctx.GetClientIdentity().GetMSPID()
-	if err != nil {
-		return fmt.Errorf("failed to get user MSP ID: %v", err)
+		return nil, err
 	}
 
 	// Log user ID and MSP ID
-	fmt.Printf("User %s from MSP %s uploaded document '%s'\n", userID, userMSPID, docName)
-
-	// Proceed with document upload logic...
-	fmt.Printf("Document '%s' uploaded successfully\n", docName)
+	fmt.Printf("User %s from MSP %s uploaded version %d of document '%s'\n", document.UploaderID, document.UploaderMSPID, document.Version, docName)
 
-	return nil
+	return document, nil
 }
 
-func main() {
-	chaincode, err := contractapi.NewChaincode(&DocumentChaincode{})
-	if err != nil {
-		fmt.Printf("Error creating DocumentChaincode: %s", err)
-		return
+// GetDocument returns the latest version of a document
+func (cc *DocumentChaincode) GetDocument(ctx contractapi.TransactionContextInterface, docName string) (*Document, error) {
+	if err := documentPolicy.Authorize("GetDocument", ctx.GetClientIdentity()); err != nil {
+		return nil, err
 	}
+	return readDocument(ctx.GetStub(), docName, 0)
+}
 
-	if err := chaincode.Start(); err != nil {
-		fmt.Printf("Error starting chaincode: %s", err)
+// GetDocumentVersion returns a version of a document, from 1
+func (cc *DocumentChaincode) GetDocumentVersion(ctx contractapi.TransactionContextInterface, docName string, version int) (*Document, error) {
+	if err := documentPolicy.Authorize("GetDocumentVersion", ctx.GetClientIdentity()); err != nil {
+		return nil, err
+	}
+	if version < 1 {
+		return nil, fmt.Errorf("invalid version %d, expecting a number from 1", version)
 	}
+	return readDocument(ctx.GetStub(), docName, version)
 }
 
-
+// ListDocuments returns the latest version of every document
+func (cc *DocumentChaincode) ListDocuments(ctx contractapi.TransactionContextInterface) ([]*Document, error) {
+	if err := documentPolicy.Authorize("ListDocuments", ctx.GetClientIdentity()); err != nil {
+		return nil, err
+	}
+	return listDocuments(ctx.GetStub())
+}
@@ -0,0 +1,213 @@
+package accesscontrol
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/canonicaljson"
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/chaincodetest"
+)
+
+const reportContent = "%PDF-1.4 quarterly report"
+
+var reportHash = sha256Hex(reportContent)
+
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestDocumentChaincodeUploadDocument(t *testing.T) {
+	tests := []struct {
+		name    string
+		attrs   map[string]string
+		wantErr bool
+	}{
+		{name: "IT admin", attrs: map[string]string{"role": "admin", "department": "IT"}},
+		{name: "HR clerk", attrs: map[string]string{"role": "clerk", "department": "HR"}, wantErr: true},
+		{name: "HR admin", attrs: map[string]string{"role": "admin", "department": "HR"}, wantErr: true},
+		{name: "IT clerk", attrs: map[string]string{"role": "clerk", "department": "IT"}, wantErr: true},
+		{name: "no attributes", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ci, err := chaincodetest.NewClientIdentity("Org1MSP", "user1", tt.attrs)
+			if err != nil {
+				t.Fatalf("failed to create client identity: %v", err)
+			}
+			ctx := chaincodetest.NewTransactionContext(ci)
+			ctx.Stub.TransientMap = map[string][]byte{ContentTransientKey: []byte(reportContent)}
+
+			_, err = new(DocumentChaincode).UploadDocument(ctx, "report.pdf", "application/pdf", reportHash)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UploadDocument() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLowLevelDocumentChaincodeUploadDocument(t *testing.T) {
+	stub := chaincodetest.NewStub("documents", new(LowLevelDocumentChaincode))
+	stub.TransientMap = map[string][]byte{ContentTransientKey: []byte(reportContent)}
+	args := [][]byte{[]byte("UploadDocument"), []byte("report.pdf"), []byte("application/pdf"), []byte(reportHash)}
+
+	admin, err := chaincodetest.NewClientIdentity("Org1MSP", "admin1", map[string]string{"role": "admin", "department": "IT"})
+	if err != nil {
+		t.Fatalf("failed to create client identity: %v", err)
+	}
+	if err := stub.SetCreator(admin); err != nil {
+		t.Fatalf("failed to set creator: %v", err)
+	}
+	if res := stub.MockInvoke("tx1", args); res.Status != shim.OK {
+		t.Errorf("UploadDocument() by IT admin failed: %s", res.Message)
+	}
+	res := stub.MockInvoke("tx2", [][]byte{[]byte("GetDocumentVersion"), []byte("report.pdf"), []byte("1")})
+	var document Document
+	if res.Status != shim.OK {
+		t.Errorf("GetDocumentVersion() failed: %s", res.Message)
+	} else if err := json.Unmarshal(res.Payload, &document); err != nil || document.SHA256 != reportHash || document.TxID != "tx1" {
+		t.Errorf("GetDocumentVersion() = %s, want version 1 uploaded in tx1", res.Payload)
+	}
+
+	// the denial explains which part of the policy the client failed
+	clerk, err := chaincodetest.NewClientIdentity("Org1MSP", "clerk1", map[string]string{"role": "clerk", "department": "IT"})
+	if err != nil {
+		t.Fatalf("failed to create client identity: %v", err)
+	}
+	if err := stub.SetCreator(clerk); err != nil {
+		t.Fatalf("failed to set creator: %v", err)
+	}
+	res = stub.MockInvoke("tx3", args)
+	if res.Status == shim.OK || !strings.Contains(res.Message, "attr.role is 'clerk', not 'admin'") {
+		t.Errorf("UploadDocument() by IT clerk = %d %q, want the denial explained", res.Status, res.Message)
+	}
+}
+
+func TestDocumentChaincodeVersions(t *testing.T) {
+	admin, err := chaincodetest.NewClientIdentity("Org1MSP", "admin1", map[string]string{"role": "admin", "department": "IT"})
+	if err != nil {
+		t.Fatalf("failed to create client identity: %v", err)
+	}
+	ctx := chaincodetest.NewTransactionContext(admin)
+	ctx.Stub.Clock = func() time.Time { return time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC) }
+	cc := new(DocumentChaincode)
+
+	upload := func(txID string, name string, content string, hash string) (*Document, error) {
+		ctx.StartTransaction(txID)
+		ctx.Stub.TransientMap = map[string][]byte{ContentTransientKey: []byte(content)}
+		return cc.UploadDocument(ctx, name, "application/pdf", hash)
+	}
+
+	if _, err := upload("tx1", "report.pdf", reportContent, reportHash); err != nil {
+		t.Fatalf("UploadDocument() error = %v", err)
+	}
+	if _, err := upload("tx2", "policy.pdf", "%PDF-1.4 policy", sha256Hex("%PDF-1.4 policy")); err != nil {
+		t.Fatalf("UploadDocument() error = %v", err)
+	}
+	v2, err := upload("tx3", "report.pdf", reportContent+" v2", strings.ToUpper(sha256Hex(reportContent+" v2")))
+	if err != nil {
+		t.Fatalf("UploadDocument() of a new version error = %v", err)
+	}
+	want := &Document{
+		Name:          "report.pdf",
+		Version:       2,
+		SHA256:        sha256Hex(reportContent + " v2"),
+		Size:          len(reportContent) + 3,
+		MIMEType:      "application/pdf",
+		UploaderID:    admin.ID,
+		UploaderMSPID: "Org1MSP",
+		UploadedAt:    "2024-01-31T12:00:00Z",
+		TxID:          "tx3",
+	}
+	if !reflect.DeepEqual(v2, want) {
+		t.Errorf("UploadDocument() = %+v, want %+v", v2, want)
+	}
+
+	// the hash must be the one of the content, and the content must be sent
+	if _, err := upload("tx4", "report.pdf", "tampered", reportHash); err == nil || !strings.Contains(err.Error(), "hash mismatch") {
+		t.Errorf("UploadDocument() with a hash mismatch error = %v", err)
+	}
+	ctx.StartTransaction("tx5")
+	ctx.Stub.TransientMap = nil
+	if _, err := cc.UploadDocument(ctx, "report.pdf", "application/pdf", reportHash); err == nil {
+		t.Errorf("UploadDocument() without content succeeded")
+	}
+	if _, err := upload("tx6", "report.pdf", reportContent, "abc"); err == nil {
+		t.Errorf("UploadDocument() with an invalid hash succeeded")
+	}
+	oversized := strings.Repeat("a", MaxDocumentSize+1)
+	if _, err := upload("tx7", "big.pdf", oversized, sha256Hex(oversized)); err == nil || !strings.Contains(err.Error(), "bigger than the maximum") {
+		t.Errorf("UploadDocument() of an oversized content error = %v", err)
+	}
+
+	// the metadata is stored as canonical json
+	key, err := ctx.Stub.CreateCompositeKey(DocumentKey, []string{"report.pdf"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := ctx.Stub.GetState(key)
+	wantJSON, _ := canonicaljson.Marshal(want)
+	if err != nil || !bytes.Equal(stored, wantJSON) {
+		t.Errorf("stored document = %s, %v, want %s", stored, err, wantJSON)
+	}
+
+	if latest, err := cc.GetDocument(ctx, "report.pdf"); err != nil || !reflect.DeepEqual(latest, want) {
+		t.Errorf("GetDocument() = %+v, %v, want %+v", latest, err, want)
+	}
+	if v1, err := cc.GetDocumentVersion(ctx, "report.pdf", 1); err != nil || v1.SHA256 != reportHash || v1.TxID != "tx1" {
+		t.Errorf("GetDocumentVersion(1) = %+v, %v, want the first upload", v1, err)
+	}
+	if _, err := cc.GetDocumentVersion(ctx, "report.pdf", 3); err == nil {
+		t.Errorf("GetDocumentVersion(3) succeeded, want does not exist")
+	}
+	if _, err := cc.GetDocument(ctx, "missing.pdf"); err == nil {
+		t.Errorf("GetDocument() of a missing document succeeded")
+	}
+
+	documents, err := cc.ListDocuments(ctx)
+	if err != nil {
+		t.Fatalf("ListDocuments() error = %v", err)
+	}
+	var listed []string
+	for _, document := range documents {
+		listed = append(listed, fmt.Sprintf("%s v%d", document.Name, document.Version))
+	}
+	if got := strings.Join(listed, ", "); got != "policy.pdf v1, report.pdf v2" {
+		t.Errorf("ListDocuments() = %s, want policy.pdf v1, report.pdf v2", got)
+	}
+
+	// the documents are read by the organizations of the channel only
+	outsider, err := chaincodetest.NewClientIdentity("Org3MSP", "user3", nil)
+	if err != nil {
+		t.Fatalf("failed to create client identity: %v", err)
+	}
+	if _, err := cc.GetDocument(chaincodetest.NewTransactionContext(outsider), "report.pdf"); err == nil {
+		t.Errorf("GetDocument() by Org3MSP succeeded")
+	}
+}
+
+func TestDecodeCreator(t *testing.T) {
+	ci, err := chaincodetest.NewClientIdentity("Org1MSP", "user1", nil)
+	if err != nil {
+		t.Fatalf("failed to create client identity: %v", err)
+	}
+	ctx := chaincodetest.NewTransactionContext(ci)
+
+	cert, err := DecodeCreator(ctx)
+	if err != nil {
+		t.Fatalf("DecodeCreator() error = %v", err)
+	}
+	if cert.Subject.CommonName != "user1" {
+		t.Errorf("DecodeCreator() subject = %s, want user1", cert.Subject)
+	}
+}
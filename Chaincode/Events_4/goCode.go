@@ -1,27 +1,47 @@
+// Package events shows how a chaincode emits an event that external applications
+// can listen to (see externalAppCode.txt for the listening side).
+package events
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/asset"
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/assetevents"
+)
+
 // Define the chaincode
 type SimpleAssetChaincode struct {
-    contractapi.Contract
+	contractapi.Contract
 }
 
 // Function to create a new asset
-func (s *SimpleAssetChaincode) CreateAsset(ctx contractapi.TransactionContextInterface, assetID string, owner string) error {
-    // Create the asset
-    asset := Asset{
-        ID:    assetID,
-        Owner: owner,
-    }
-
-    // Store the asset in the world state
-    err := ctx.GetStub().PutState(assetID, []byte(owner))
-    if err != nil {
-        return err
-    }
-
-    // Emit an event indicating a new asset was created
-    err = ctx.GetStub().SetEvent("AssetCreated", []byte(assetID))
-    if err != nil {
-        return fmt.Errorf("error emitting event: %v", err)
-    }
-
-    return nil
+func (s *SimpleAssetChaincode) CreateAsset(ctx contractapi.TransactionContextInterface, assetID string, owner string, color string, size int, price int) error {
+	newAsset := asset.Asset{
+		ID:    assetID,
+		Owner: owner,
+		Color: color,
+		Size:  size,
+		Price: price,
+	}
+
+	// Create the asset the way the high level chaincode does: asset.Create validates it,
+	// rejects an ID that exists already and binds the owner to the client, so a second call
+	// with the same ID fails instead of overwriting the asset and announcing it twice
+	err := asset.Create(ctx.GetStub(), &newAsset)
+	if err != nil {
+		return err
+	}
+
+	// Emit an event indicating a new asset was created
+	// A bare payload like []byte(assetID) leaves every listener guessing its format, so the event
+	// is the typed assetevents.AssetCreated instead: a versioned json document with the transaction ID,
+	// the client that created the asset and the asset itself (see externalAppCode.txt for the decoding side)
+	err = assetevents.EmitChange(ctx.GetStub(), nil, &newAsset)
+	if err != nil {
+		return fmt.Errorf("error emitting event: %v", err)
+	}
+
+	return nil
 }
@@ -0,0 +1,65 @@
+package events
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/assetevents"
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/chaincodetest"
+)
+
+func TestCreateAsset(t *testing.T) {
+	chaincode, err := contractapi.NewChaincode(new(SimpleAssetChaincode))
+	if err != nil {
+		t.Fatalf("failed to create chaincode: %v", err)
+	}
+	stub := chaincodetest.NewStub("events", chaincode)
+	alice, err := chaincodetest.NewClientIdentity("Org1MSP", "Alice", nil)
+	if err != nil {
+		t.Fatalf("failed to create identity: %v", err)
+	}
+	if err := stub.SetCreator(alice); err != nil {
+		t.Fatalf("failed to set creator: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		args        []string
+		wantMessage string
+	}{
+		{name: "create", args: []string{"CreateAsset", "asset1", "Alice", "red", "5", "100"}},
+		{name: "duplicate", args: []string{"CreateAsset", "asset1", "Alice", "blue", "5", "100"}, wantMessage: "the asset asset1 already exists"},
+		{name: "invalid", args: []string{"CreateAsset", "asset2", "Alice", "", "5", "0"}, wantMessage: "asset asset2 is invalid: 2 violation(s)"},
+	}
+	for _, tt := range tests {
+		byteArgs := make([][]byte, 0, len(tt.args))
+		for _, arg := range tt.args {
+			byteArgs = append(byteArgs, []byte(arg))
+		}
+		response := stub.MockInvoke(tt.name, byteArgs)
+
+		var emitted []string
+		select {
+		case e := <-stub.ChaincodeEventsChannel:
+			event, err := assetevents.Decode(e.EventName, e.Payload)
+			if err != nil {
+				t.Fatalf("%s: Decode failed: %v", tt.name, err)
+			}
+			emitted = append(emitted, event.EventHeader().Name+" "+event.EventHeader().AssetID)
+		default:
+		}
+
+		if tt.wantMessage != "" {
+			if response.Status == shim.OK || !strings.Contains(response.Message, tt.wantMessage) || len(emitted) != 0 {
+				t.Errorf("%s: got %d (%s) with events %v, want an error containing %q and no event", tt.name, response.Status, response.Message, emitted, tt.wantMessage)
+			}
+			continue
+		}
+		if response.Status != shim.OK || len(emitted) != 1 || emitted[0] != assetevents.NameAssetCreated+" asset1" {
+			t.Errorf("%s: got %d (%s) with events %v, want OK with the AssetCreated event of asset1", tt.name, response.Status, response.Message, emitted)
+		}
+	}
+}
@@ -18,117 +18,140 @@
 // AssetExists: Checks if a specific asset exists in the ledger.
 // GetHistoryForAsset: Retrieves the transaction history for a specific asset.
 
+// The functions themselves are written in the highlevel package
+// (FirstHighLevel_2A.go and combined_IMP_3.go), here we only call them one after the other.
 
-// 1. CreateAsset:
+// Package methods walks through the standard methods of SimpleAssetChaincode
+// and the functions of the chaincode stub that they are built on.
+package methods
+
+import (
+	"fmt"
 
-// Create a new asset
-asset := Asset{
-    ID:    "1",
-    Owner: "Alice",
-    Color: "Red",
-    Size:  10,
-    Price: 100,
-}
-
-// Call the CreateAsset function
-err := s.CreateAsset(ctx, asset)
-if err != nil {
-    fmt.Printf("Error creating asset: %v\n", err)
-    return
-}
-
-
-// 2. UpdateAsset:
-// Update an existing asset
-updatedAsset := Asset{
-    ID:    "1",
-    Owner: "Bob",
-    Color: "Blue",
-    Size:  20,
-    Price: 200,
-}
-
-// Call the UpdateAsset function
-err := s.UpdateAsset(ctx, updatedAsset)
-if err != nil {
-    fmt.Printf("Error updating asset: %v\n", err)
-    return
-}
-
-
-// 3. TransferAssetOwnership:
-// Transfer ownership of an asset
-newOwner := "Charlie"
-
-// Call the TransferAssetOwnership function
-err := s.TransferAssetOwnership(ctx, "1", newOwner)
-// "1": This parameter represents the unique identifier (ID) of the asset whose ownership is being transferred
-if err != nil {
-    fmt.Printf("Error transferring asset ownership: %v\n", err)
-    return
-}
-
-
-// 4. DeleteAsset:
-// Delete an existing asset
-assetIDToDelete := "1"
-
-// Call the DeleteAsset function
-err := s.DeleteAsset(ctx, assetIDToDelete)
-if err != nil {
-    fmt.Printf("Error deleting asset: %v\n", err)
-    return
-}
-
-
-// 5. QueryAssetByID:
-// Query an asset by its ID
-assetIDToQuery := "1"
-
-// Call the QueryAssetByID function
-asset, err := s.QueryAssetByID(ctx, assetIDToQuery)
-if err != nil {
-    fmt.Printf("Error querying asset by ID: %v\n", err)
-    return
-}
-
-fmt.Println("Asset:", asset)
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/highlevel"
+)
 
-// 6. QueryAllAssets:
-// Query all assets
-assets, err := s.QueryAllAssets(ctx)
-if err != nil {
-    fmt.Printf("Error querying all assets: %v\n", err)
-    return
-}
+// StandardMethodsWalkthrough calls every standard method of SimpleAssetChaincode on asset "1"
+func StandardMethodsWalkthrough(ctx contractapi.TransactionContextInterface) {
+	s := new(highlevel.SimpleAssetChaincode)
 
-fmt.Println("All assets:", assets)
 
+	// 1. CreateAsset:
+
+	// Create a new asset
+	asset := highlevel.Asset{
+		ID:    "1",
+		Owner: "Alice",
+		Color: "red",
+		Size:  10,
+		Price: 100,
+	}
 
-// 7.AssetExists:
-// Check if an asset exists
-assetIDToCheck := "1"
+	// Call the CreateAsset function
+	err := s.CreateAsset(ctx, asset)
+	if err != nil {
+		fmt.Printf("Error creating asset: %v\n", err)
+		return
+	}
+
+
+	// 2. UpdateAsset:
+	// Update an existing asset
+	updatedAsset := highlevel.Asset{
+		ID:    "1",
+		Owner: "Bob",
+		Color: "blue",
+		Size:  20,
+		Price: 200,
+	}
+
+	// Call the UpdateAsset function
+	err = s.UpdateAsset(ctx, updatedAsset)
+	if err != nil {
+		fmt.Printf("Error updating asset: %v\n", err)
+		return
+	}
+
+
+	// 3. TransferAssetOwnership:
+	// Transfer ownership of an asset
+	newOwner := "Charlie"
+	newOwnerMSPID := "Org1MSP"
+
+	// Call the TransferAssetOwnership function
+	err = s.TransferAssetOwnership(ctx, "1", newOwner, newOwnerMSPID)
+	// "1": This parameter represents the unique identifier (ID) of the asset whose ownership is being transferred
+	// newOwnerMSPID: the display names of every MSP are apart, Charlie is looked up among those of Org1MSP
+	if err != nil {
+		fmt.Printf("Error transferring asset ownership: %v\n", err)
+		return
+	}
+
+
+	// 5. QueryAssetByID:
+	// Query an asset by its ID
+	assetIDToQuery := "1"
+
+	// Call the QueryAssetByID function
+	assetJSON, err := s.QueryAssetByID(ctx, assetIDToQuery)
+	if err != nil {
+		fmt.Printf("Error querying asset by ID: %v\n", err)
+		return
+	}
+
+	fmt.Println("Asset:", assetJSON)
+
+
+	// 6. QueryAllAssets:
+	// Query all assets
+	assets, err := s.QueryAllAssets(ctx)
+	if err != nil {
+		fmt.Printf("Error querying all assets: %v\n", err)
+		return
+	}
+
+	fmt.Println("All assets:", assets)
+
+
+	// 7.AssetExists:
+	// Check if an asset exists
+	assetIDToCheck := "1"
+
+	// Call the AssetExists function
+	exists, err := s.AssetExists(ctx, assetIDToCheck)
+	if err != nil {
+		fmt.Printf("Error checking asset existence: %v\n", err)
+		return
+	}
+
+	fmt.Println("Asset exists:", exists)
 
-// Call the AssetExists function
-exists, err := s.AssetExists(ctx, assetIDToCheck)
-if err != nil {
-    fmt.Printf("Error checking asset existence: %v\n", err)
-    return
+
+	// 8. GetHistoryForAsset:
+	// Get transaction history for an asset
+	assetIDToQuery = "1"
+
+	// Call the GetHistoryForAsset function
+	history, err := s.GetHistoryForAsset(ctx, assetIDToQuery)
+	if err != nil {
+		fmt.Printf("Error getting history for asset: %v\n", err)
+		return
+	}
+
+	fmt.Println("History for asset:", history)
+
+
+	// 4. DeleteAsset:
+	// (called last, so that the queries above still find the asset)
+	// Delete an existing asset
+	assetIDToDelete := "1"
+
+	// Call the DeleteAsset function
+	err = s.DeleteAsset(ctx, assetIDToDelete)
+	if err != nil {
+		fmt.Printf("Error deleting asset: %v\n", err)
+		return
+	}
 }
-
-fmt.Println("Asset exists:", exists)
-
-
-// 8. GetHistoryForAsset:
-// Get transaction history for an asset
-assetIDToQuery := "1"
-
-// Call the GetHistoryForAsset function
-history, err := s.GetHistoryForAsset(ctx, assetIDToQuery)
-if err != nil {
-    fmt.Printf("Error getting history for asset: %v\n", err)
-    return
-}
-
-fmt.Println("History for asset:", history)
\ No newline at end of file
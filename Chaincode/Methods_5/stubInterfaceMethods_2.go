@@ -21,6 +21,17 @@
 // GetPrivateDataByRange: Retrieves a range of private data based on key range.
 // GetPrivateDataByPartialCompositeKey: Retrieves private data based on a partial composite key.
 
+package methods
+
+import (
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// SimpleChaincode is only used to show the stub functions below
+type SimpleChaincode struct{}
+
 
 // 1. GetArgs retrieves the arguments of the transaction.
 func (cc *SimpleChaincode) GetArgsExample(stub shim.ChaincodeStubInterface) [][]byte {
@@ -52,7 +63,8 @@ func (cc *SimpleChaincode) GetTxTimestampExample(stub shim.ChaincodeStubInterfac
 	if err != nil {
 		return time.Time{}, err
 	}
-	return timestamp, nil
+	// the stub gives back a protobuf timestamp, AsTime() converts it to a go time.Time
+	return timestamp.AsTime(), nil
 }
 
 
@@ -0,0 +1,124 @@
+package abac
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/chaincodetest"
+)
+
+const testPolicy = `{
+  "policies": {
+    "UploadDocument": {"allow": "attr.role == 'admin' && attr.department == 'IT'"},
+    "GetDocument": {"allow": "mspid in ['Org1MSP', 'Org2MSP'] || ou == 'auditor'"},
+    "ArchiveDocument": {"allow": "!(attr.role != 'archivist') && attr.hf.EnrollmentID != 'intern'"},
+    "ShareDocument": {"allow": "!(ou == 'contractor') && !!(mspid == 'Org1MSP')"},
+    "Maintenance": {"allow": "false"}
+  }
+}`
+
+func newClient(t *testing.T, mspID string, ous []string, attrs map[string]string) *chaincodetest.ClientIdentity {
+	t.Helper()
+	ci, err := chaincodetest.NewClientIdentity(mspID, "user1", attrs, ous...)
+	if err != nil {
+		t.Fatalf("failed to create client identity: %v", err)
+	}
+	return ci
+}
+
+func TestAuthorize(t *testing.T) {
+	policy, err := Load([]byte(testPolicy))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		function   string
+		mspID      string
+		ous        []string
+		attrs      map[string]string
+		wantReason string // empty when the access is allowed
+	}{
+		{name: "IT admin", function: "UploadDocument", mspID: "Org1MSP", attrs: map[string]string{"role": "admin", "department": "IT"}},
+		// the two callers the old `role != "admin" && department != "IT"` check let through
+		{name: "HR admin", function: "UploadDocument", mspID: "Org1MSP", attrs: map[string]string{"role": "admin", "department": "HR"},
+			wantReason: "attr.department is 'HR', not 'IT'"},
+		{name: "IT clerk", function: "UploadDocument", mspID: "Org1MSP", attrs: map[string]string{"role": "clerk", "department": "IT"},
+			wantReason: "attr.role is 'clerk', not 'admin'"},
+		{name: "no attributes", function: "UploadDocument", mspID: "Org1MSP",
+			wantReason: "attr.role is not set, not 'admin'"},
+		{name: "member MSP", function: "GetDocument", mspID: "Org2MSP"},
+		{name: "auditor OU", function: "GetDocument", mspID: "Org3MSP", ous: []string{"client", "auditor"}},
+		{name: "other MSP", function: "GetDocument", mspID: "Org3MSP", ous: []string{"client"},
+			wantReason: "mspid is 'Org3MSP', not one of ['Org1MSP', 'Org2MSP'] and ou is 'client', not 'auditor'"},
+		{name: "archivist", function: "ArchiveDocument", mspID: "Org1MSP", attrs: map[string]string{"role": "archivist", "hf.EnrollmentID": "bob"}},
+		{name: "archivist intern", function: "ArchiveDocument", mspID: "Org1MSP", attrs: map[string]string{"role": "archivist", "hf.EnrollmentID": "intern"},
+			wantReason: "attr.hf.EnrollmentID is 'intern'"},
+		// a negation that denies tells which condition it negates
+		{name: "clerk archive", function: "ArchiveDocument", mspID: "Org1MSP", attrs: map[string]string{"role": "clerk"},
+			wantReason: "!(attr.role is 'clerk', not 'archivist')"},
+		{name: "employee share", function: "ShareDocument", mspID: "Org1MSP", ous: []string{"employee"}},
+		{name: "contractor share", function: "ShareDocument", mspID: "Org1MSP", ous: []string{"contractor"},
+			wantReason: "!(ou is 'contractor')"},
+		{name: "other MSP share", function: "ShareDocument", mspID: "Org2MSP", ous: []string{"employee"},
+			wantReason: "!(!(mspid is 'Org2MSP', not 'Org1MSP'))"},
+		{name: "closed function", function: "Maintenance", mspID: "Org1MSP", wantReason: "the policy is false"},
+		{name: "no policy", function: "DeleteDocument", mspID: "Org1MSP", attrs: map[string]string{"role": "admin", "department": "IT"},
+			wantReason: "no policy for the function"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newClient(t, tt.mspID, tt.ous, tt.attrs)
+			err := policy.Authorize(tt.function, client)
+			if tt.wantReason == "" {
+				if err != nil {
+					t.Errorf("Authorize() error = %v, want allowed", err)
+				}
+				return
+			}
+
+			var denied *DeniedError
+			if !errors.As(err, &denied) {
+				t.Fatalf("Authorize() error = %v, want a DeniedError", err)
+			}
+			if denied.Decision.Allowed || denied.Decision.Reason != tt.wantReason {
+				t.Errorf("Authorize() reason = %q, want %q", denied.Decision.Reason, tt.wantReason)
+			}
+			if !strings.Contains(err.Error(), client.ID) || !strings.Contains(err.Error(), tt.function) {
+				t.Errorf("Authorize() error = %q, want the function and client in it", err)
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		allow   string
+		wantErr string
+	}{
+		{allow: "", wantErr: "unexpected end of the expression"},
+		{allow: "attr.role = 'admin'", wantErr: "unexpected '=' at offset 10"},
+		{allow: "role == 'admin'", wantErr: `unexpected "role" at offset 0, expecting mspid, id, ou or attr.<name>`},
+		{allow: "attr.role == admin", wantErr: `unexpected "admin" at offset 13, expecting a quoted string`},
+		{allow: "mspid in ['Org1MSP'", wantErr: "unexpected end of the expression, expecting ']'"},
+		{allow: "(mspid == 'Org1MSP'", wantErr: "unexpected end of the expression, expecting ')'"},
+		{allow: "mspid == 'Org1MSP' ou == 'x'", wantErr: `unexpected "ou" at offset 19`},
+		{allow: "mspid == 'Org1MSP", wantErr: "unterminated string at offset 9"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.allow, func(t *testing.T) {
+			_, err := New(Document{Policies: map[string]Rule{"UploadDocument": {Allow: tt.allow}}})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("New() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := Load([]byte(`{"policies": [`)); err == nil {
+		t.Errorf("Load() of invalid json succeeded")
+	}
+}
@@ -0,0 +1,398 @@
+package abac
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+)
+
+// The policy expressions
+//
+//	expr       := and ( "||" and )*
+//	and        := unary ( "&&" unary )*
+//	unary      := "!" unary | "(" expr ")" | "true" | "false" | comparison
+//	comparison := operand ( "==" | "!=" ) string | operand "in" "[" string ( "," string )* "]"
+//	operand    := "mspid" | "id" | "ou" | "attr." name
+//	string     := '...' | "..."
+//
+// mspid and id are the MSP ID and the ID of the client, ou the organizational units of its
+// certificate and attr.name the value of the attribute name of its certificate (see cid).
+// A client can have several OUs: ou == 'x' is true if one of them is x. An attribute the client
+// does not have has no value: attr.role == 'admin' is false and attr.role != 'admin' is true.
+
+// node is a parsed expression
+type node interface {
+	// eval evaluates the expression for the client and explains the result
+	eval(client cid.ClientIdentity) (bool, string, error)
+}
+
+type literal bool
+
+func (l literal) eval(client cid.ClientIdentity) (bool, string, error) {
+	return bool(l), fmt.Sprintf("the policy is %v", bool(l)), nil
+}
+
+// not is true if its operand is false. It is explained by the explanation of its operand in !( ),
+// e.g. !(attr.role is 'intern') when the policy is !(attr.role == 'intern') and the client is an intern
+type not struct {
+	operand node
+}
+
+func (n *not) eval(client cid.ClientIdentity) (bool, string, error) {
+	result, explanation, err := n.operand.eval(client)
+	if err != nil {
+		return false, "", err
+	}
+	return !result, "!(" + explanation + ")", nil
+}
+
+// and is true if all its operands are, it is explained by the first false operand
+// or by all the operands when it is true
+type and struct {
+	operands []node
+}
+
+func (a *and) eval(client cid.ClientIdentity) (bool, string, error) {
+	explanations := make([]string, 0, len(a.operands))
+	for _, operand := range a.operands {
+		result, explanation, err := operand.eval(client)
+		if err != nil || !result {
+			return false, explanation, err
+		}
+		explanations = append(explanations, explanation)
+	}
+	return true, strings.Join(explanations, " and "), nil
+}
+
+// or is true if one of its operands is, it is explained by the first true operand
+// or by all the operands when it is false
+type or struct {
+	operands []node
+}
+
+func (o *or) eval(client cid.ClientIdentity) (bool, string, error) {
+	explanations := make([]string, 0, len(o.operands))
+	for _, operand := range o.operands {
+		result, explanation, err := operand.eval(client)
+		if err != nil || result {
+			return result, explanation, err
+		}
+		explanations = append(explanations, explanation)
+	}
+	return false, strings.Join(explanations, " and "), nil
+}
+
+// comparison compares the values of an operand with one value (== and !=) or a list (in)
+type comparison struct {
+	operand string
+	op      string
+	values  []string
+}
+
+func (c *comparison) eval(client cid.ClientIdentity) (bool, string, error) {
+	clientValues, err := operandValues(client, c.operand)
+	if err != nil {
+		return false, "", err
+	}
+
+	match := false
+	for _, clientValue := range clientValues {
+		for _, value := range c.values {
+			match = match || clientValue == value
+		}
+	}
+
+	// explain with the values of the client, and what they were compared to when they don't match
+	explanation := describe(c.operand, clientValues)
+	if !match {
+		switch c.op {
+		case "in":
+			explanation += ", not one of " + quoteAll(c.values)
+		default:
+			explanation += ", not " + quote(c.values[0])
+		}
+	}
+	if c.op == "!=" {
+		return !match, explanation, nil
+	}
+	return match, explanation, nil
+}
+
+// operandValues returns the values of operand for the client, none for an attribute it does not have
+func operandValues(client cid.ClientIdentity, operand string) ([]string, error) {
+	switch {
+	case operand == "mspid":
+		mspID, err := client.GetMSPID()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get client MSP ID: %v", err)
+		}
+		return []string{mspID}, nil
+	case operand == "id":
+		id, err := client.GetID()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get client ID: %v", err)
+		}
+		return []string{id}, nil
+	case operand == "ou":
+		cert, err := client.GetX509Certificate()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get client certificate: %v", err)
+		}
+		if cert == nil {
+			return nil, nil
+		}
+		return cert.Subject.OrganizationalUnit, nil
+	default:
+		name := strings.TrimPrefix(operand, "attr.")
+		value, found, err := client.GetAttributeValue(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get client attribute %s: %v", name, err)
+		}
+		if !found {
+			return nil, nil
+		}
+		return []string{value}, nil
+	}
+}
+
+func describe(operand string, values []string) string {
+	switch len(values) {
+	case 0:
+		return operand + " is not set"
+	case 1:
+		return operand + " is " + quote(values[0])
+	default:
+		return operand + " is " + quoteAll(values)
+	}
+}
+
+func quote(value string) string {
+	return "'" + value + "'"
+}
+
+func quoteAll(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, quote(value))
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// token kinds
+const (
+	tokenEOF = iota
+	tokenIdent
+	tokenString
+	tokenSymbol
+)
+
+type token struct {
+	kind   int
+	text   string
+	offset int
+}
+
+// tokenize splits an expression into identifiers, strings and the symbols == != && || ! ( ) [ ] ,
+func tokenize(expression string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expression); {
+		c := rune(expression[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '\'' || c == '"':
+			end := strings.IndexRune(expression[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string at offset %d", i)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: expression[i+1 : i+1+end], offset: i})
+			i += end + 2
+		case isIdentRune(c):
+			start := i
+			for i < len(expression) && isIdentRune(rune(expression[i])) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: expression[start:i], offset: start})
+		default:
+			symbol := ""
+			for _, s := range []string{"==", "!=", "&&", "||", "!", "(", ")", "[", "]", ","} {
+				if strings.HasPrefix(expression[i:], s) {
+					symbol = s
+					break
+				}
+			}
+			if symbol == "" {
+				return nil, fmt.Errorf("unexpected %q at offset %d", c, i)
+			}
+			tokens = append(tokens, token{kind: tokenSymbol, text: symbol, offset: i})
+			i += len(symbol)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, offset: len(expression)}), nil
+}
+
+// isIdentRune tells if c can be part of an identifier. Attribute names like hf.EnrollmentID contain dots.
+func isIdentRune(c rune) bool {
+	return c == '_' || c == '.' || c == '-' || c < unicode.MaxASCII && (unicode.IsLetter(c) || unicode.IsDigit(c))
+}
+
+// parser is a recursive descent parser of the grammar above
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// parse parses a policy expression
+func parse(expression string) (node, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if next := p.peek(); next.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at offset %d", next.text, next.offset)
+	}
+	return n, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token if it is the symbol or keyword text
+func (p *parser) accept(text string) bool {
+	if t := p.peek(); (t.kind == tokenSymbol || t.kind == tokenIdent) && t.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(text string) error {
+	if !p.accept(text) {
+		return unexpected(p.peek(), "'"+text+"'")
+	}
+	return nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	operands := []node{first}
+	for p.accept("||") {
+		operand, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+	}
+	if len(operands) == 1 {
+		return first, nil
+	}
+	return &or{operands: operands}, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	first, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	operands := []node{first}
+	for p.accept("&&") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+	}
+	if len(operands) == 1 {
+		return first, nil
+	}
+	return &and{operands: operands}, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	switch {
+	case p.accept("!"):
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &not{operand: operand}, nil
+	case p.accept("("):
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return n, p.expect(")")
+	case p.accept("true"):
+		return literal(true), nil
+	case p.accept("false"):
+		return literal(false), nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	operand := p.next()
+	if operand.kind != tokenIdent || !isOperand(operand.text) {
+		return nil, unexpected(operand, "mspid, id, ou or attr.<name>")
+	}
+
+	switch op := p.next(); {
+	case op.kind == tokenSymbol && (op.text == "==" || op.text == "!="):
+		value := p.next()
+		if value.kind != tokenString {
+			return nil, unexpected(value, "a quoted string")
+		}
+		return &comparison{operand: operand.text, op: op.text, values: []string{value.text}}, nil
+	case op.kind == tokenIdent && op.text == "in":
+		if err := p.expect("["); err != nil {
+			return nil, err
+		}
+		var values []string
+		for {
+			value := p.next()
+			if value.kind != tokenString {
+				return nil, unexpected(value, "a quoted string")
+			}
+			values = append(values, value.text)
+			if !p.accept(",") {
+				break
+			}
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		return &comparison{operand: operand.text, op: "in", values: values}, nil
+	default:
+		return nil, unexpected(op, "==, != or in")
+	}
+}
+
+func isOperand(name string) bool {
+	return name == "mspid" || name == "id" || name == "ou" || strings.HasPrefix(name, "attr.") && len(name) > len("attr.")
+}
+
+func unexpected(t token, expecting string) error {
+	if t.kind == tokenEOF {
+		return fmt.Errorf("unexpected end of the expression, expecting %s", expecting)
+	}
+	return fmt.Errorf("unexpected %q at offset %d, expecting %s", t.text, t.offset, expecting)
+}
@@ -0,0 +1,140 @@
+// Package abac is an attribute based access control policy engine for chaincodes.
+//
+// A policy document maps the transaction functions of a chaincode to a policy expression
+// over the identity of the client: the attributes of its certificate, its MSP ID and OUs.
+//
+//	{
+//	  "policies": {
+//	    "UploadDocument": {
+//	      "description": "only the admins of the IT department upload documents",
+//	      "allow": "attr.role == 'admin' && attr.department == 'IT'"
+//	    },
+//	    "GetDocument": {
+//	      "allow": "mspid in ['Org1MSP', 'Org2MSP'] || ou == 'auditor'"
+//	    }
+//	  }
+//	}
+//
+// Load parses the document once, so that a mistake in a policy fails when the chaincode starts
+// rather than when the function is called. Authorize then evaluates the policy of a function for
+// the client of a transaction. It denies by default: a function without a policy is denied to
+// everybody, and the error of a denial explains which part of the policy the client failed.
+package abac
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+)
+
+// Rule is the policy of one transaction function in a policy document
+type Rule struct {
+	Description string `json:"description,omitempty"`
+	// Allow is the expression a client must satisfy to call the function
+	Allow string `json:"allow"`
+}
+
+// Document is a policy document, the rules of the transaction functions by function name
+type Document struct {
+	Policies map[string]Rule `json:"policies"`
+}
+
+// Policy is a loaded policy document
+type Policy struct {
+	rules       map[string]Rule
+	expressions map[string]node
+}
+
+// Load parses a json policy document and all its expressions
+func Load(documentJSON []byte) (*Policy, error) {
+	var document Document
+	if err := json.Unmarshal(documentJSON, &document); err != nil {
+		return nil, fmt.Errorf("failed to read policy document: %v", err)
+	}
+	return New(document)
+}
+
+// New parses the expressions of a policy document
+func New(document Document) (*Policy, error) {
+	policy := &Policy{rules: document.Policies, expressions: map[string]node{}}
+	for function, rule := range document.Policies {
+		expression, err := parse(rule.Allow)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse policy of %s: %v", function, err)
+		}
+		policy.expressions[function] = expression
+	}
+	return policy, nil
+}
+
+// Decision is the outcome of the evaluation of the policy of a function for a client
+type Decision struct {
+	Function string
+	Allowed  bool
+	// Policy is the expression of the function, empty if it has none
+	Policy string
+	// Reason explains the outcome from the identity of the client, e.g. "attr.role is 'clerk', not 'admin'"
+	Reason   string
+	ClientID string
+	MSPID    string
+}
+
+func (d *Decision) String() string {
+	outcome := "denied"
+	if d.Allowed {
+		outcome = "allowed"
+	}
+	if d.Policy == "" {
+		return fmt.Sprintf("access to %s %s to client %s of %s: %s", d.Function, outcome, d.ClientID, d.MSPID, d.Reason)
+	}
+	return fmt.Sprintf("access to %s %s to client %s of %s: %s (policy: %s)", d.Function, outcome, d.ClientID, d.MSPID, d.Reason, d.Policy)
+}
+
+// DeniedError is the error of Authorize when the policy denies the access
+type DeniedError struct {
+	Decision *Decision
+}
+
+func (e *DeniedError) Error() string {
+	return e.Decision.String()
+}
+
+// Evaluate evaluates the policy of function for client
+func (p *Policy) Evaluate(function string, client cid.ClientIdentity) (*Decision, error) {
+	clientID, err := client.GetID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client ID: %v", err)
+	}
+	mspID, err := client.GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	decision := &Decision{Function: function, ClientID: clientID, MSPID: mspID}
+
+	// deny by default: a function nobody wrote a policy for is closed, not open
+	expression, ok := p.expressions[function]
+	if !ok {
+		decision.Reason = "no policy for the function"
+		return decision, nil
+	}
+
+	decision.Policy = p.rules[function].Allow
+	decision.Allowed, decision.Reason, err = expression.eval(client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate policy of %s: %v", function, err)
+	}
+	return decision, nil
+}
+
+// Authorize returns a *DeniedError if the policy of function denies the access to client
+func (p *Policy) Authorize(function string, client cid.ClientIdentity) error {
+	decision, err := p.Evaluate(function, client)
+	if err != nil {
+		return err
+	}
+	if !decision.Allowed {
+		return &DeniedError{Decision: decision}
+	}
+	return nil
+}
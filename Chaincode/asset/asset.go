@@ -0,0 +1,98 @@
+// Package asset holds the Asset document that is shared by every SimpleAssetChaincode
+// in this repo, the contractapi one (high level) and the shim one (low level).
+//
+// Keeping the struct, its validation and its (de)serialization in one place means
+// that an asset written by one chaincode can be read back by the other without
+// losing fields because of a different json tag or a missing field.
+package asset
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/canonicaljson"
+)
+
+// DocType is the value stored in Asset.DocType for every asset document
+const DocType = "asset"
+
+// Values of Asset.Status
+const (
+	StatusActive   = "active"
+	StatusInactive = "inactive"
+	StatusDeleted  = "deleted"
+)
+
+// Asset represents a single asset
+// It gets stored in the ledger like this in the form of key value pairs:
+// ID : {"ID": "1", "color": "red", "docType": "asset", "owner": "salil", ..., "version": 1}  (in canonical json format, keys sorted)
+//
+// DocType, Version, OwnerMSPID, OwnerID, UpdatedBy, DeletedBy and DeletedAt are filled in by the chaincode and not by the client.
+// Version is bumped on every write, so the first CreateAsset stores version 1,
+// the next UpdateAsset version 2 and so on.
+// UpdatedBy is the ID of the client that submitted the last write (see SubmitterID),
+// so the history tells who made every change.
+// Owner is the display name of the owner, OwnerMSPID and OwnerID are its client identity (see owner.go).
+// DeletedBy and DeletedAt are set when the asset is soft deleted (Status deleted, see lifecycle.go).
+// The `metadata:",optional"` tag tells contractapi that a client may leave a field out.
+// The `validate` tag holds the rules checked by Validate (see validateStruct in validate.go).
+type Asset struct {
+	DocType string `json:"docType" metadata:",optional" validate:"enum=asset"`
+	ID      string `json:"ID" validate:"required,max=64,pattern=^[A-Za-z0-9][A-Za-z0-9_.-]*$"`
+	Owner   string `json:"owner" validate:"required,max=128"`
+	Color   string `json:"color" validate:"required,enum=red|blue|green|yellow|black|white"`
+	Size    int    `json:"size" validate:"min=0,max=1000000"`
+	Price   int    `json:"price" validate:"min=1,max=1000000000"`
+	Status  string `json:"status,omitempty" metadata:",optional" validate:"enum=active|inactive|deleted"`
+	Version int    `json:"version" metadata:",optional" validate:"min=0"`
+
+	OwnerMSPID string `json:"ownerMspId,omitempty" metadata:",optional"`
+	OwnerID    string `json:"ownerId,omitempty" metadata:",optional"`
+	UpdatedBy  string `json:"updatedBy,omitempty" metadata:",optional"`
+	DeletedBy  string `json:"deletedBy,omitempty" metadata:",optional"`
+	DeletedAt  string `json:"deletedAt,omitempty" metadata:",optional"`
+}
+
+// Validate checks that the asset can be stored in the ledger.
+// It checks every rule and returns a *ValidationError listing all the violations, or nil.
+func (a *Asset) Validate() error {
+	violations := validateStruct("asset", a)
+	if len(violations) > 0 {
+		return &ValidationError{
+			Code:       CodeValidationFailed,
+			Message:    fmt.Sprintf("asset %s is invalid: %d violation(s)", a.ID, len(violations)),
+			Violations: violations,
+		}
+	}
+	return nil
+}
+
+// Marshal serializes the asset into the json document stored in the ledger.
+// It also stamps the asset with DocType.
+// The document is canonical json (sorted keys, normalized numbers), so every endorsing
+// peer writes exactly the same bytes for the same asset.
+func (a *Asset) Marshal() ([]byte, error) {
+	a.DocType = DocType
+
+	assetJSON, err := canonicaljson.Marshal(a)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal asset: %v", err)
+	}
+	return assetJSON, nil
+}
+
+// Unmarshal deserializes an asset json document, either read from the ledger
+// or received from a client.
+// Note that encoding/json matches keys case-insensitively, so documents written
+// with the old "id" tag of Json_3.go are read into ID as well.
+func Unmarshal(assetJSON []byte) (*Asset, error) {
+	a := new(Asset)
+	err := json.Unmarshal(assetJSON, a)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal asset: %v", err)
+	}
+	if a.DocType != "" && a.DocType != DocType {
+		return nil, fmt.Errorf("document of type %q is not an asset", a.DocType)
+	}
+	return a, nil
+}
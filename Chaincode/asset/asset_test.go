@@ -0,0 +1,32 @@
+package asset
+
+import (
+	"testing"
+)
+
+// TestMarshalCanonical checks that an asset always gives the same bytes,
+// however the client wrote its json
+func TestMarshalCanonical(t *testing.T) {
+	payloads := []string{
+		`{"ID":"asset1","owner":"Alice","color":"red","size":5,"price":100}`,
+		`{"price":1e2,"size":5.0,"color":"red","owner":"Alice","ID":"asset1"}`,
+		` { "color" : "red", "ID" : "asset1", "size" : 5, "owner" : "Alice", "price" : 100.00 } `,
+	}
+	want := `{"ID":"asset1","color":"red","docType":"asset","owner":"Alice","price":100,"size":5,"version":0}`
+
+	for _, payload := range payloads {
+		for run := 0; run < 10; run++ {
+			a, err := UnmarshalStrict([]byte(payload))
+			if err != nil {
+				t.Fatalf("UnmarshalStrict(%s) failed: %v", payload, err)
+			}
+			got, err := a.Marshal()
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			if string(got) != want {
+				t.Fatalf("Marshal of %s = %s, want %s", payload, got, want)
+			}
+		}
+	}
+}
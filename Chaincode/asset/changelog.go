@@ -0,0 +1,173 @@
+package asset
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// Actions of a ChangeLogEntry
+const (
+	ActionCreated  = "created"
+	ActionUpdated  = "updated"
+	ActionDeleted  = "deleted"
+	ActionRestored = "restored"
+)
+
+// bookkeepingFields are written by the chaincode on every write, a change of
+// one of them alone is not a change of the asset
+var bookkeepingFields = map[string]bool{
+	"docType":   true,
+	"version":   true,
+	"updatedBy": true,
+	"deletedBy": true,
+	"deletedAt": true,
+}
+
+// FieldChange is the change of one field of an asset, with the values as text
+// ("" when the field was not set)
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// String formats the change like owner: Alice→Bob
+func (c FieldChange) String() string {
+	return fmt.Sprintf("%s: %s→%s", c.Field, c.From, c.To)
+}
+
+// ChangeLogEntry tells what one transaction changed in an asset and who submitted it.
+// ClientID is empty when the transaction did not record its submitter
+// (deletes, and writes made before UpdatedBy existed).
+type ChangeLogEntry struct {
+	TxId      string        `json:"txId"`
+	Timestamp string        `json:"timestamp"`
+	Action    string        `json:"action"`
+	ClientID  string        `json:"clientId,omitempty" metadata:",optional"`
+	Changes   []FieldChange `json:"changes"`
+}
+
+// SubmitterID returns the ID of the client that submitted the transaction,
+// or "" when the stub has no (readable) creator
+func SubmitterID(stub shim.ChaincodeStubInterface) string {
+	id, err := cid.GetID(stub)
+	if err != nil {
+		return ""
+	}
+	return id
+}
+
+// QueryChangeLog returns, for every transaction that wrote the asset, the fields it changed.
+// It is computed from the history of the asset by comparing every snapshot with the previous one,
+// and is in chronological order (oldest transaction first), unlike the history.
+func QueryChangeLog(stub shim.ChaincodeStubInterface, assetID string) ([]*ChangeLogEntry, error) {
+	history, err := QueryHistory(stub, assetID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	changeLog := make([]*ChangeLogEntry, 0, len(history))
+	var previous *Asset
+	for i := len(history) - 1; i >= 0; i-- {
+		entry := history[i]
+		logEntry := &ChangeLogEntry{
+			TxId:      entry.TxId,
+			Timestamp: entry.Timestamp,
+			Changes:   []FieldChange{},
+		}
+
+		// A soft delete (or a restore) is a write and not a delete of the key,
+		// it is told apart by the status it sets
+		switch {
+		case entry.IsDelete:
+			logEntry.Action = ActionDeleted
+		case previous == nil:
+			logEntry.Action = ActionCreated
+		case entry.Asset != nil && entry.Asset.IsDeleted() && !previous.IsDeleted():
+			logEntry.Action = ActionDeleted
+		case entry.Asset != nil && !entry.Asset.IsDeleted() && previous.IsDeleted():
+			logEntry.Action = ActionRestored
+		default:
+			logEntry.Action = ActionUpdated
+		}
+		if entry.Asset != nil {
+			logEntry.ClientID = entry.Asset.UpdatedBy
+			logEntry.Changes, err = diff(previous, entry.Asset)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		changeLog = append(changeLog, logEntry)
+		previous = entry.Asset
+	}
+	return changeLog, nil
+}
+
+// diff returns the fields that differ between before (nil for none) and after, by json name
+func diff(before *Asset, after *Asset) ([]FieldChange, error) {
+	oldFields, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+	newFields, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(newFields))
+	for name := range oldFields {
+		names = append(names, name)
+	}
+	for name := range newFields {
+		if _, ok := oldFields[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	changes := []FieldChange{}
+	for _, name := range names {
+		from, to := oldFields[name], newFields[name]
+		if bookkeepingFields[name] || from == to {
+			continue
+		}
+		changes = append(changes, FieldChange{Field: name, From: from, To: to})
+	}
+	return changes, nil
+}
+
+// fields returns every json field of a as text
+func fields(a *Asset) (map[string]string, error) {
+	values := map[string]string{}
+	if a == nil {
+		return values, nil
+	}
+
+	assetJSON, err := json.Marshal(a)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal asset: %v", err)
+	}
+	var decoded map[string]interface{}
+	err = json.Unmarshal(assetJSON, &decoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal asset: %v", err)
+	}
+
+	for name, value := range decoded {
+		if s, ok := value.(string); ok {
+			values[name] = s
+			continue
+		}
+		text, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal field %s: %v", name, err)
+		}
+		values[name] = string(text)
+	}
+	return values, nil
+}
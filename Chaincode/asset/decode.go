@@ -0,0 +1,121 @@
+package asset
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/canonicaljson"
+)
+
+// MaxPayloadSize is the largest json payload DecodeStrict accepts, in bytes
+const MaxPayloadSize = 16 * 1024
+
+// DecodeStrict decodes the json payload sent by a client into v, rejecting
+// anything that plain json.Unmarshal would silently accept:
+//
+//   - payloads bigger than MaxPayloadSize
+//   - fields that v does not have
+//   - the same key twice in an object (also "ID" and "id", which json.Unmarshal would both put in ID)
+//   - anything after the json value
+//
+// Numbers are normalized before they are decoded, so 5.0 and 5e0 are read as the integer 5,
+// while 5.5 still fails to decode into an int.
+//
+// Its signature is the one of json.Unmarshal, so it can replace it, e.g. as router.Router.Decode.
+func DecodeStrict(data []byte, v interface{}) error {
+	if len(data) > MaxPayloadSize {
+		return fmt.Errorf("payload of %d bytes is bigger than the maximum of %d bytes", len(data), MaxPayloadSize)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	value, err := readValue(dec)
+	if err != nil {
+		return fmt.Errorf("invalid json: %v", err)
+	}
+	_, err = dec.Token()
+	if err != io.EOF {
+		return fmt.Errorf("invalid json: unexpected data after the json value")
+	}
+
+	normalized, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to normalize json: %v", err)
+	}
+
+	dec = json.NewDecoder(bytes.NewReader(normalized))
+	dec.DisallowUnknownFields()
+	err = dec.Decode(v)
+	if err != nil {
+		return fmt.Errorf("invalid json: %v", err)
+	}
+	return nil
+}
+
+// UnmarshalStrict is Unmarshal with DecodeStrict, for assets received from a client
+func UnmarshalStrict(assetJSON []byte) (*Asset, error) {
+	a := new(Asset)
+	err := DecodeStrict(assetJSON, a)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal asset: %v", err)
+	}
+	return a, nil
+}
+
+// readValue reads the next json value of dec token by token, which lets it see
+// duplicate keys, and normalizes its numbers
+func readValue(dec *json.Decoder) (interface{}, error) {
+	token, err := dec.Token()
+	if err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	switch t := token.(type) {
+	case json.Delim:
+		if t == '[' {
+			values := []interface{}{}
+			for dec.More() {
+				value, err := readValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				values = append(values, value)
+			}
+			_, err = dec.Token() // ]
+			return values, err
+		}
+
+		object := map[string]interface{}{}
+		seen := map[string]string{}
+		for dec.More() {
+			keyToken, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key := keyToken.(string)
+			if previous, ok := seen[strings.ToLower(key)]; ok {
+				return nil, fmt.Errorf("duplicate key %q (already set as %q)", key, previous)
+			}
+			seen[strings.ToLower(key)] = key
+
+			object[key], err = readValue(dec)
+			if err != nil {
+				return nil, err
+			}
+		}
+		_, err = dec.Token() // }
+		return object, err
+	case json.Number:
+		return canonicaljson.NormalizeNumber(t)
+	default:
+		// string, bool or nil
+		return t, nil
+	}
+}
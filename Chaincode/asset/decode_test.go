@@ -0,0 +1,52 @@
+package asset
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnmarshalStrict(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    Asset
+		wantErr string
+	}{
+		{
+			name:    "valid",
+			payload: `{"ID":"asset1","owner":"Alice","color":"red","size":5,"price":100}`,
+			want:    Asset{ID: "asset1", Owner: "Alice", Color: "red", Size: 5, Price: 100},
+		},
+		{
+			name:    "normalized numbers",
+			payload: `{"ID":"asset1","owner":"Alice","color":"red","size":5.0,"price":1e2}`,
+			want:    Asset{ID: "asset1", Owner: "Alice", Color: "red", Size: 5, Price: 100},
+		},
+		{name: "fraction", payload: `{"ID":"asset1","size":5.5}`, wantErr: "cannot unmarshal number 5.5"},
+		{name: "unknown field", payload: `{"ID":"asset1","weight":3}`, wantErr: `unknown field "weight"`},
+		{name: "duplicate key", payload: `{"ID":"asset1","owner":"Alice","owner":"Mallory"}`, wantErr: `duplicate key "owner"`},
+		{name: "duplicate key in another case", payload: `{"ID":"asset1","id":"asset2"}`, wantErr: `duplicate key "id"`},
+		{name: "trailing data", payload: `{"ID":"asset1"} {"ID":"asset2"}`, wantErr: "unexpected data after the json value"},
+		{name: "truncated", payload: `{"ID":"asset1"`, wantErr: "invalid json"},
+		{name: "wrong type", payload: `{"ID":"asset1","price":"100"}`, wantErr: "cannot unmarshal string"},
+		{name: "oversized", payload: `{"ID":"` + strings.Repeat("a", MaxPayloadSize) + `"}`, wantErr: "bigger than the maximum"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UnmarshalStrict([]byte(tt.payload))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UnmarshalStrict failed: %v", err)
+			}
+			if *got != tt.want {
+				t.Errorf("asset = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
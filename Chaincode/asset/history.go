@@ -0,0 +1,110 @@
+package asset
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// HistoryEntry is one write of an asset, as found in the history of its key.
+// Asset is the decoded asset written by the transaction, it is not set when
+// the transaction deleted the asset (IsDelete).
+// Timestamp is the time of the transaction in RFC3339 (UTC).
+type HistoryEntry struct {
+	TxId      string `json:"txId"`
+	Timestamp string `json:"timestamp"`
+	IsDelete  bool   `json:"isDelete"`
+	Asset     *Asset `json:"asset,omitempty" metadata:",optional"`
+}
+
+// HistoryFilter restricts the history to the transactions from From to To (both included)
+// and to the Limit most recent ones. A zero From, To or Limit does not restrict anything.
+type HistoryFilter struct {
+	From  time.Time
+	To    time.Time
+	Limit int
+}
+
+// ParseHistoryFilter builds a HistoryFilter from the arguments of a transaction:
+// from and to are RFC3339 times or empty, limit is 0 for no limit
+func ParseHistoryFilter(from string, to string, limit int) (*HistoryFilter, error) {
+	filter := &HistoryFilter{Limit: limit}
+	if limit < 0 {
+		return nil, fmt.Errorf("limit must not be negative, got %d", limit)
+	}
+
+	var err error
+	if from != "" {
+		filter.From, err = time.Parse(time.RFC3339, from)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from time %q, expecting RFC3339: %v", from, err)
+		}
+	}
+	if to != "" {
+		filter.To, err = time.Parse(time.RFC3339, to)
+		if err != nil {
+			return nil, fmt.Errorf("invalid to time %q, expecting RFC3339: %v", to, err)
+		}
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return nil, fmt.Errorf("to time %s is before from time %s", to, from)
+	}
+	return filter, nil
+}
+
+// keeps tells whether a transaction made at t passes the time range of the filter
+func (f *HistoryFilter) keeps(t time.Time) bool {
+	if !f.From.IsZero() && t.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && t.After(f.To) {
+		return false
+	}
+	return true
+}
+
+// QueryHistory returns the history of the asset, newest first like GetHistoryForKey,
+// restricted by filter (nil for the whole history)
+func QueryHistory(stub shim.ChaincodeStubInterface, assetID string, filter *HistoryFilter) ([]*HistoryEntry, error) {
+	if filter == nil {
+		filter = new(HistoryFilter)
+	}
+
+	resultsIterator, err := stub.GetHistoryForKey(assetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve history for asset %s from ledger: %v", assetID, err)
+	}
+	defer resultsIterator.Close()
+
+	history := []*HistoryEntry{}
+	for resultsIterator.HasNext() {
+		if filter.Limit > 0 && len(history) == filter.Limit {
+			break
+		}
+
+		modification, err := resultsIterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate over history: %v", err)
+		}
+
+		txTime := modification.Timestamp.AsTime()
+		if !filter.keeps(txTime) {
+			continue
+		}
+
+		entry := &HistoryEntry{
+			TxId:      modification.TxId,
+			Timestamp: txTime.UTC().Format(time.RFC3339Nano),
+			IsDelete:  modification.IsDelete,
+		}
+		if !modification.IsDelete {
+			entry.Asset, err = Unmarshal(modification.Value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode asset %s written by transaction %s: %v", assetID, modification.TxId, err)
+			}
+		}
+		history = append(history, entry)
+	}
+	return history, nil
+}
@@ -0,0 +1,134 @@
+package asset
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// Names of the composite key indexes kept next to the assets.
+// An index entry is a composite key like color~id\x00red\x00asset1\x00 with an
+// empty value: the key alone tells which asset has which color, so
+// "all red assets" is a GetStateByPartialCompositeKey instead of a scan of the whole ledger.
+// The owner~id index is keyed by the owner identity (OwnerMSPID and OwnerID, see BindOwner), not
+// by the display name: owner~id\x00Org1MSP\x00x509::CN=Alice,...\x00asset1\x00. An asset
+// without an owner identity is in no owner's entries.
+const (
+	OwnerIndex = "owner~id"
+	ColorIndex = "color~id"
+)
+
+// indexValue is the value of every index entry.
+// PutState deletes a key when its value is empty, so it can't be nil.
+var indexValue = []byte{0x00}
+
+// indexedAttributes returns the values of every indexed attribute of a.
+// A soft deleted asset has none: it is left out of the indexes like a deleted one.
+func indexedAttributes(a *Asset) map[string][]string {
+	if a.IsDeleted() {
+		return nil
+	}
+	attributes := map[string][]string{
+		ColorIndex: {a.Color},
+	}
+	if a.OwnerID != "" {
+		attributes[OwnerIndex] = []string{a.OwnerMSPID, a.OwnerID}
+	}
+	return attributes
+}
+
+// UpdateIndexes makes the index entries of an asset follow its change from before to after.
+// before is nil when the asset is created and after is nil when it is deleted.
+// A soft delete (after has Status deleted) removes the entries and a restore puts them back.
+// Entries whose attribute did not change are left alone, so they stay out of the write set.
+func UpdateIndexes(stub shim.ChaincodeStubInterface, before *Asset, after *Asset) error {
+	var oldValues, newValues map[string][]string
+	id := ""
+	if before != nil {
+		oldValues = indexedAttributes(before)
+		id = before.ID
+	}
+	if after != nil {
+		newValues = indexedAttributes(after)
+		id = after.ID
+	}
+
+	for _, index := range []string{OwnerIndex, ColorIndex} {
+		oldValue, hadOld := oldValues[index]
+		newValue, hasNew := newValues[index]
+		if hadOld && hasNew && reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+
+		if hadOld {
+			key, err := stub.CreateCompositeKey(index, append(oldValue, id))
+			if err != nil {
+				return fmt.Errorf("failed to create %s key: %v", index, err)
+			}
+			err = stub.DelState(key)
+			if err != nil {
+				return fmt.Errorf("failed to delete %s entry of asset %s: %v", index, id, err)
+			}
+		}
+		if hasNew {
+			key, err := stub.CreateCompositeKey(index, append(newValue, id))
+			if err != nil {
+				return fmt.Errorf("failed to create %s key: %v", index, err)
+			}
+			err = stub.PutState(key, indexValue)
+			if err != nil {
+				return fmt.Errorf("failed to put %s entry of asset %s: %v", index, id, err)
+			}
+		}
+	}
+	return nil
+}
+
+// QueryByOwner returns the assets of the client that registered the display name owner of mspID,
+// read through the owner~id index
+func QueryByOwner(stub shim.ChaincodeStubInterface, owner string, mspID string) ([]*Asset, error) {
+	displayName, err := ResolveDisplayName(stub, owner, mspID)
+	if err != nil {
+		return nil, err
+	}
+	return QueryByIndex(stub, OwnerIndex, displayName.MSPID, displayName.ClientID)
+}
+
+// QueryByIndex returns the assets whose indexed attribute (OwnerIndex or ColorIndex) is values:
+// the color for ColorIndex, the MSP ID and the client ID of the owner for OwnerIndex
+func QueryByIndex(stub shim.ChaincodeStubInterface, index string, values ...string) ([]*Asset, error) {
+	resultsIterator, err := stub.GetStateByPartialCompositeKey(index, values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s index: %v", index, err)
+	}
+	defer resultsIterator.Close()
+
+	assets := []*Asset{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate over results: %v", err)
+		}
+
+		_, attributes, err := stub.SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split %s key: %v", index, err)
+		}
+		id := attributes[len(attributes)-1]
+
+		assetBytes, err := stub.GetState(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read asset %s from world state: %v", id, err)
+		}
+		if assetBytes == nil {
+			return nil, fmt.Errorf("%s index points to asset %s, which does not exist", index, id)
+		}
+		a, err := Unmarshal(assetBytes)
+		if err != nil {
+			return nil, err
+		}
+		assets = append(assets, a)
+	}
+	return assets, nil
+}
@@ -0,0 +1,212 @@
+package asset
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// Soft delete
+//
+// DelState removes an asset from the world state for good: every document that refers
+// to its ID now points to nothing, and only the history still knows about it.
+// With soft delete the asset stays in the world state with Status deleted, DeletedBy and
+// DeletedAt, it is hidden from QueryAllAssets and from the owner~id and color~id indexes,
+// and RestoreAsset can bring it back.
+//
+// Which of the two DeleteAsset does is the delete mode of the ledger. It is stored in the world
+// state and not in the configuration of the peers: two peers configured differently would write
+// different sets for the same DeleteAsset proposal, and the endorsements would not match.
+
+// Delete modes, see DeleteMode
+const (
+	DeleteModeHard = "hard"
+	DeleteModeSoft = "soft"
+)
+
+// DeleteModeKey is the object type of the composite key holding the delete mode.
+// Composite keys are not returned by GetStateByRange, so QueryAll never reads it as an asset.
+const DeleteModeKey = "config~deleteMode"
+
+// DeleteMode returns the delete mode of the ledger, DeleteModeHard until an admin sets another one
+func DeleteMode(stub shim.ChaincodeStubInterface) (string, error) {
+	key, err := stub.CreateCompositeKey(DeleteModeKey, []string{})
+	if err != nil {
+		return "", fmt.Errorf("failed to create delete mode key: %v", err)
+	}
+	mode, err := stub.GetState(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to read delete mode from world state: %v", err)
+	}
+	if mode == nil {
+		return DeleteModeHard, nil
+	}
+	return string(mode), nil
+}
+
+// SetDeleteMode stores the delete mode of the ledger, only an admin (see AdminRole) may change it
+func SetDeleteMode(stub shim.ChaincodeStubInterface, mode string) error {
+	if mode != DeleteModeHard && mode != DeleteModeSoft {
+		return fmt.Errorf("delete mode must be %s or %s, got %q", DeleteModeHard, DeleteModeSoft, mode)
+	}
+	if !isAdmin(stub) {
+		return fmt.Errorf("only a client with role %s can set the delete mode", AdminRole)
+	}
+	key, err := stub.CreateCompositeKey(DeleteModeKey, []string{})
+	if err != nil {
+		return fmt.Errorf("failed to create delete mode key: %v", err)
+	}
+	err = stub.PutState(key, []byte(mode))
+	if err != nil {
+		return fmt.Errorf("failed to put delete mode to world state: %v", err)
+	}
+	return nil
+}
+
+// Create stores a new asset received from a client, every CreateAsset of the chaincodes goes through it:
+// it fails if an asset with the same ID exists, if the asset is invalid or written as deleted,
+// and it binds the owner to the client that submitted the transaction (see BindOwner).
+// It stores the asset at version 1 and adds it to the indexes, the chaincode emits its AssetCreated event.
+func Create(stub shim.ChaincodeStubInterface, a *Asset) error {
+	err := checkNew(stub, a)
+	if err != nil {
+		return err
+	}
+	err = a.CheckClientLifecycle()
+	if err != nil {
+		return err
+	}
+	err = a.BindOwner(stub)
+	if err != nil {
+		return err
+	}
+	return putNew(stub, a)
+}
+
+// Seed stores a new asset like Create, but leaves its owner unbound: the asset keeps the display name
+// in owner without an owner identity, so only an admin may change it (see CheckOwner). InitLedger seeds its assets with it.
+func Seed(stub shim.ChaincodeStubInterface, a *Asset) error {
+	err := checkNew(stub, a)
+	if err != nil {
+		return err
+	}
+	return putNew(stub, a)
+}
+
+// checkNew fails if an asset with the ID of a exists or if a is invalid
+func checkNew(stub shim.ChaincodeStubInterface, a *Asset) error {
+	existing, err := stub.GetState(a.ID)
+	if err != nil {
+		return fmt.Errorf("failed to read asset %s from world state: %v", a.ID, err)
+	}
+	if existing != nil {
+		return fmt.Errorf("the asset %s already exists", a.ID)
+	}
+	return a.Validate()
+}
+
+// putNew stores a new asset at version 1 and adds it to the indexes
+func putNew(stub shim.ChaincodeStubInterface, a *Asset) error {
+	a.Version = 1
+	a.UpdatedBy = SubmitterID(stub)
+
+	// The whole asset is stored and not just the owner, so that QueryAsset
+	// can give back exactly what was created
+	assetJSON, err := a.Marshal()
+	if err != nil {
+		return err
+	}
+	err = stub.PutState(a.ID, assetJSON)
+	if err != nil {
+		return fmt.Errorf("failed to create asset: %v", err)
+	}
+	return UpdateIndexes(stub, nil, a)
+}
+
+// IsDeleted tells if the asset was soft deleted
+func (a *Asset) IsDeleted() bool {
+	return a.Status == StatusDeleted
+}
+
+// CheckNotDeleted returns an error if the stored asset was soft deleted, it has to be
+// restored before it can be updated, transferred or deleted again
+func (a *Asset) CheckNotDeleted() error {
+	if a.IsDeleted() {
+		return fmt.Errorf("the asset %s is deleted, restore it with RestoreAsset first", a.ID)
+	}
+	return nil
+}
+
+// CheckClientLifecycle returns an error if an asset received from a client tries to set
+// the lifecycle fields that only DeleteAsset and RestoreAsset may write
+func (a *Asset) CheckClientLifecycle() error {
+	if a.IsDeleted() || a.DeletedBy != "" || a.DeletedAt != "" {
+		return fmt.Errorf("the asset %s cannot be written as deleted, use DeleteAsset", a.ID)
+	}
+	return nil
+}
+
+// MarkDeleted soft deletes the asset: it sets Status deleted, and the submitter and
+// the time of the transaction in DeletedBy and DeletedAt.
+// The previous status is not kept, RestoreAsset makes the asset active.
+func (a *Asset) MarkDeleted(stub shim.ChaincodeStubInterface) error {
+	if err := a.CheckNotDeleted(); err != nil {
+		return err
+	}
+
+	// The time of the transaction (and not time.Now()) is the same on every endorsing peer
+	now, err := txTime(stub)
+	if err != nil {
+		return err
+	}
+
+	a.Status = StatusDeleted
+	a.DeletedBy = SubmitterID(stub)
+	a.DeletedAt = now.Format(time.RFC3339Nano)
+	a.UpdatedBy = a.DeletedBy
+	a.Version++
+	return nil
+}
+
+// Restore makes a soft deleted asset active again.
+// Only the client that deleted the asset, or an admin (see AdminRole), may restore it.
+func (a *Asset) Restore(stub shim.ChaincodeStubInterface) error {
+	if !a.IsDeleted() {
+		return fmt.Errorf("the asset %s is not deleted", a.ID)
+	}
+
+	clientID := SubmitterID(stub)
+	if (clientID == "" || clientID != a.DeletedBy) && !isAdmin(stub) {
+		return fmt.Errorf("client %q may not restore the asset %s, only the client that deleted it or a client with role %s can", clientID, a.ID, AdminRole)
+	}
+
+	a.Status = StatusActive
+	a.DeletedBy = ""
+	a.DeletedAt = ""
+	a.UpdatedBy = clientID
+	a.Version++
+	return nil
+}
+
+// ActiveOnly returns the assets that are not soft deleted, in the same order
+func ActiveOnly(assets []*Asset) []*Asset {
+	active := make([]*Asset, 0, len(assets))
+	for _, a := range assets {
+		if !a.IsDeleted() {
+			active = append(active, a)
+		}
+	}
+	return active
+}
+
+// DeletedOnly returns the soft deleted assets, in the same order
+func DeletedOnly(assets []*Asset) []*Asset {
+	deleted := make([]*Asset, 0, len(assets))
+	for _, a := range assets {
+		if a.IsDeleted() {
+			deleted = append(deleted, a)
+		}
+	}
+	return deleted
+}
@@ -0,0 +1,253 @@
+package asset
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/canonicaljson"
+)
+
+// Owner identities
+//
+// The owner of an asset is the client identity stored in OwnerMSPID and OwnerID (the MSP ID and
+// the ID of its certificate, see cid.GetMSPID and cid.GetID), and only that client may update,
+// transfer or delete the asset. Those IDs are long x509::CN=...::... strings, so the asset also
+// keeps the readable Owner, a display name. Every display name belongs to one client of one MSP: the
+// mapping is stored in the ledger, written by RegisterDisplayName or on the first CreateAsset that uses
+// the name, and a transfer to "Bob" of Org1MSP goes to the client of Org1MSP that registered Bob.
+//
+// The display names of every MSP are apart, each MSP issues its own certificates and CN=Bob of
+// Org2MSP has nothing to do with CN=Bob of Org1MSP. Within its MSP, a client may only claim its own
+// name, the common name of its certificate (CN=Bob may register "Bob" or "bob"), otherwise anybody
+// could register Bob first and receive the assets meant for him. Any other name is mapped to a client
+// by an admin of the same MSP (AssignDisplayName).
+
+// DisplayNameDocType is the value stored in DisplayName.DocType
+const DisplayNameDocType = "displayName"
+
+// DisplayNameKey is the object type of the composite key of a display name,
+// made of the MSP ID and the name
+const DisplayNameKey = "msp~name"
+
+// AdminRole is the value of the "role" attribute of the client certificate of an admin.
+// An admin may restore any deleted asset, take over an asset that has no owner identity,
+// and assign display names to the clients of its MSP.
+const AdminRole = "admin"
+
+// DisplayName maps a display name to the client identity it belongs to
+type DisplayName struct {
+	DocType  string `json:"docType"`
+	Name     string `json:"name"`
+	MSPID    string `json:"mspId"`
+	ClientID string `json:"clientId"`
+}
+
+// Submitter returns the MSP ID and the ID of the client that submitted the transaction
+func Submitter(stub shim.ChaincodeStubInterface) (string, string, error) {
+	mspID, err := cid.GetMSPID(stub)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	clientID, err := cid.GetID(stub)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get client ID: %v", err)
+	}
+	return mspID, clientID, nil
+}
+
+// isAdmin tells if the client has the role attribute AdminRole
+func isAdmin(stub shim.ChaincodeStubInterface) bool {
+	role, _, err := cid.GetAttributeValue(stub, "role")
+	return err == nil && role == AdminRole
+}
+
+// CheckAdmin fails unless the client has the role attribute AdminRole, action tells what it tried to do
+func CheckAdmin(stub shim.ChaincodeStubInterface, action string) error {
+	if !isAdmin(stub) {
+		return fmt.Errorf("only a client with role %s can %s", AdminRole, action)
+	}
+	return nil
+}
+
+// RegisterDisplayName maps name, among the display names of its MSP, to the client that submitted
+// the transaction. name has to be the common name of its certificate (in any case).
+// Registering a name again is a no-op, registering a name that belongs to another client fails.
+func RegisterDisplayName(stub shim.ChaincodeStubInterface, name string) (*DisplayName, error) {
+	mspID, clientID, err := Submitter(stub)
+	if err != nil {
+		return nil, err
+	}
+	displayName := &DisplayName{DocType: DisplayNameDocType, Name: name, MSPID: mspID, ClientID: clientID}
+
+	// a name the client already has, assigned by an admin or not, is fine
+	existing, err := readDisplayName(stub, name, mspID)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		cert, err := cid.GetX509Certificate(stub)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get client certificate: %v", err)
+		}
+		if cert == nil || !strings.EqualFold(cert.Subject.CommonName, name) {
+			return nil, fmt.Errorf("client %s of %s can't register the display name %s, only the common name of its certificate, ask a client with role %s to assign it", clientID, mspID, name, AdminRole)
+		}
+	}
+	return putDisplayName(stub, displayName, existing)
+}
+
+// AssignDisplayName maps name to the client identity mspID and clientID (see cid.GetID),
+// whatever the common name of its certificate. Only a client with the role attribute AdminRole
+// of the MSP mspID may do so.
+func AssignDisplayName(stub shim.ChaincodeStubInterface, name string, mspID string, clientID string) (*DisplayName, error) {
+	if !isAdmin(stub) {
+		return nil, fmt.Errorf("only a client with role %s can assign a display name", AdminRole)
+	}
+	if mspID == "" || clientID == "" {
+		return nil, fmt.Errorf("the MSP ID and the client ID of the display name %s are required", name)
+	}
+	adminMSPID, err := cid.GetMSPID(stub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	if adminMSPID != mspID {
+		return nil, fmt.Errorf("a client with role %s of %s can't assign the display names of %s", AdminRole, adminMSPID, mspID)
+	}
+
+	existing, err := readDisplayName(stub, name, mspID)
+	if err != nil {
+		return nil, err
+	}
+	displayName := &DisplayName{DocType: DisplayNameDocType, Name: name, MSPID: mspID, ClientID: clientID}
+	return putDisplayName(stub, displayName, existing)
+}
+
+// putDisplayName stores displayName, unless existing (the stored mapping of the name, if any)
+// already maps it to the same client or to another one
+func putDisplayName(stub shim.ChaincodeStubInterface, displayName *DisplayName, existing *DisplayName) (*DisplayName, error) {
+	name := displayName.Name
+	if name == "" || len(name) > 128 {
+		return nil, fmt.Errorf("display name must be 1 to 128 characters long, got %q", name)
+	}
+	if existing != nil {
+		if existing.MSPID != displayName.MSPID || existing.ClientID != displayName.ClientID {
+			return nil, fmt.Errorf("the display name %s belongs to another client of %s", name, existing.MSPID)
+		}
+		return existing, nil
+	}
+
+	key, err := displayNameKey(stub, name, displayName.MSPID)
+	if err != nil {
+		return nil, err
+	}
+	displayNameJSON, err := canonicaljson.Marshal(displayName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal display name: %v", err)
+	}
+	err = stub.PutState(key, displayNameJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put display name %s: %v", name, err)
+	}
+	return displayName, nil
+}
+
+// ResolveDisplayName returns the client identity that registered name among the display names of mspID
+func ResolveDisplayName(stub shim.ChaincodeStubInterface, name string, mspID string) (*DisplayName, error) {
+	displayName, err := readDisplayName(stub, name, mspID)
+	if err != nil {
+		return nil, err
+	}
+	if displayName == nil {
+		return nil, fmt.Errorf("no client of %s registered the display name %s", mspID, name)
+	}
+	return displayName, nil
+}
+
+// BindOwner makes the client that submitted the transaction the owner of a new asset.
+// The display name in Owner has to belong to that client, it is registered for it if nobody
+// of its MSP has it yet and it is the common name of the client (see RegisterDisplayName).
+func (a *Asset) BindOwner(stub shim.ChaincodeStubInterface) error {
+	displayName, err := RegisterDisplayName(stub, a.Owner)
+	if err != nil {
+		return err
+	}
+	a.SetOwner(displayName)
+	return nil
+}
+
+// SetOwner makes the client of displayName the owner of the asset
+func (a *Asset) SetOwner(displayName *DisplayName) {
+	a.Owner = displayName.Name
+	a.OwnerMSPID = displayName.MSPID
+	a.OwnerID = displayName.ClientID
+}
+
+// CheckOwner returns an error unless the client that submitted the transaction owns the asset.
+// An asset written before owners were bound to clients has no owner identity,
+// only an admin may change it (and bind it by transferring it).
+func (a *Asset) CheckOwner(stub shim.ChaincodeStubInterface) error {
+	if a.OwnerID == "" {
+		if isAdmin(stub) {
+			return nil
+		}
+		return fmt.Errorf("the asset %s has no owner identity, only a client with role %s can change it", a.ID, AdminRole)
+	}
+
+	mspID, clientID, err := Submitter(stub)
+	if err != nil {
+		return err
+	}
+	if mspID != a.OwnerMSPID || clientID != a.OwnerID {
+		return fmt.Errorf("client %s of %s is not the owner of the asset %s (%s)", clientID, mspID, a.ID, a.Owner)
+	}
+	return nil
+}
+
+// CheckUpdate checks that the client that submitted the transaction owns the stored asset and that
+// the updated asset keeps its owner (TransferAssetOwnership changes it), then copies the owner identity over
+func (a *Asset) CheckUpdate(stub shim.ChaincodeStubInterface, existing *Asset) error {
+	err := existing.CheckOwner(stub)
+	if err != nil {
+		return err
+	}
+	if a.Owner != existing.Owner {
+		return fmt.Errorf("UpdateAsset can't change the owner of the asset %s from %s to %s, transfer it instead", a.ID, existing.Owner, a.Owner)
+	}
+	a.OwnerMSPID = existing.OwnerMSPID
+	a.OwnerID = existing.OwnerID
+	return nil
+}
+
+func displayNameKey(stub shim.ChaincodeStubInterface, name string, mspID string) (string, error) {
+	key, err := stub.CreateCompositeKey(DisplayNameKey, []string{mspID, name})
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s key: %v", DisplayNameKey, err)
+	}
+	return key, nil
+}
+
+// readDisplayName returns the mapping of name among the display names of mspID, or nil if nobody registered it
+func readDisplayName(stub shim.ChaincodeStubInterface, name string, mspID string) (*DisplayName, error) {
+	key, err := displayNameKey(stub, name, mspID)
+	if err != nil {
+		return nil, err
+	}
+	displayNameJSON, err := stub.GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read display name %s of %s: %v", name, mspID, err)
+	}
+	if displayNameJSON == nil {
+		return nil, nil
+	}
+
+	displayName := new(DisplayName)
+	err = json.Unmarshal(displayNameJSON, displayName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal display name: %v", err)
+	}
+	return displayName, nil
+}
@@ -0,0 +1,39 @@
+package asset
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// Page is one page of assets, as returned by the paginated queries of both
+// SimpleAssetChaincodes. Pass Bookmark to the next call to get the next page,
+// an empty Bookmark means there is no page left.
+type Page struct {
+	Records      []*Asset `json:"records"`
+	FetchedCount int32    `json:"fetchedCount"`
+	Bookmark     string   `json:"bookmark"`
+}
+
+// QueryPage reads the page of at most pageSize assets that starts at bookmark
+// (or at the first asset when bookmark is empty) with GetStateByRangeWithPagination,
+// so that only one page is ever loaded in memory.
+// Soft deleted assets are left out of Records, so a page may hold less than pageSize
+// assets although FetchedCount (the number of documents read) is pageSize.
+func QueryPage(stub shim.ChaincodeStubInterface, pageSize int32, bookmark string) (*Page, error) {
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("page size must be positive, got %d", pageSize)
+	}
+
+	resultsIterator, metadata, err := stub.GetStateByRangeWithPagination("", "", pageSize, bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve assets from ledger: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	records, err := readAssets(resultsIterator)
+	if err != nil {
+		return nil, err
+	}
+	return &Page{Records: ActiveOnly(records), FetchedCount: metadata.FetchedRecordsCount, Bookmark: metadata.Bookmark}, nil
+}
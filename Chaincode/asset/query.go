@@ -0,0 +1,127 @@
+package asset
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// assetQuery turns the selector sent by a client into a CouchDB query that only returns assets.
+// selectorJSON is either a bare selector ({"owner": "Alice"}) or a full query
+// ({"selector": {"owner": "Alice"}, "sort": [{"price": "desc"}]}).
+// The selector always gets "docType": "asset", so the query can't read other documents,
+// and the indexes of META-INF/statedb/couchdb/indexes (which start with docType) can be used.
+// It is also ANDed with notDeleted, so that soft deleted assets are hidden like in the other queries.
+// Filtering them in the query and not in the results keeps the pages of QueryPageBySelector full.
+func assetQuery(selectorJSON string) (string, error) {
+	var query map[string]interface{}
+	err := json.Unmarshal([]byte(selectorJSON), &query)
+	if err != nil {
+		return "", fmt.Errorf("selector must be a json object: %v", err)
+	}
+
+	selector := query
+	if s, ok := query["selector"]; ok {
+		selector, ok = s.(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("selector must be a json object, got %v", s)
+		}
+	} else {
+		query = map[string]interface{}{"selector": selector}
+	}
+	query["selector"] = map[string]interface{}{
+		"docType": DocType,
+		"$and":    []interface{}{selector, notDeleted()},
+	}
+
+	queryJSON, err := json.Marshal(query)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal query: %v", err)
+	}
+	return string(queryJSON), nil
+}
+
+// notDeleted is the selector of the assets that are not soft deleted.
+// Status is left out of the json of an active asset (omitempty), and in CouchDB a missing
+// field doesn't match $ne, so it has to be matched with $exists as well.
+func notDeleted() map[string]interface{} {
+	return map[string]interface{}{
+		"$or": []interface{}{
+			map[string]interface{}{"status": map[string]interface{}{"$exists": false}},
+			map[string]interface{}{"status": map[string]interface{}{"$ne": StatusDeleted}},
+		},
+	}
+}
+
+// QueryAll returns every asset of the ledger, soft deleted ones included, with GetStateByRange
+func QueryAll(stub shim.ChaincodeStubInterface) ([]*Asset, error) {
+	resultsIterator, err := stub.GetStateByRange("", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve assets from ledger: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	return readAssets(resultsIterator)
+}
+
+// QueryBySelector returns the assets matching the CouchDB selector with GetQueryResult,
+// soft deleted ones excluded.
+// It only works on peers that use CouchDB as state database (or on chaincodetest.Stub).
+func QueryBySelector(stub shim.ChaincodeStubInterface, selectorJSON string) ([]*Asset, error) {
+	query, err := assetQuery(selectorJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsIterator, err := stub.GetQueryResult(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run query: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	return readAssets(resultsIterator)
+}
+
+// QueryPageBySelector returns one page of the assets matching the CouchDB selector
+// with GetQueryResultWithPagination, soft deleted ones excluded. The bookmark is the one CouchDB returns.
+func QueryPageBySelector(stub shim.ChaincodeStubInterface, selectorJSON string, pageSize int32, bookmark string) (*Page, error) {
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("page size must be positive, got %d", pageSize)
+	}
+	query, err := assetQuery(selectorJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsIterator, metadata, err := stub.GetQueryResultWithPagination(query, pageSize, bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run query: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	records, err := readAssets(resultsIterator)
+	if err != nil {
+		return nil, err
+	}
+	return &Page{Records: records, FetchedCount: metadata.FetchedRecordsCount, Bookmark: metadata.Bookmark}, nil
+}
+
+// readAssets reads every asset of a query result.
+// The slice is never nil, contractapi rejects a null where the schema says array.
+func readAssets(resultsIterator shim.StateQueryIteratorInterface) ([]*Asset, error) {
+	assets := []*Asset{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate over results: %v", err)
+		}
+
+		a, err := Unmarshal(queryResponse.Value)
+		if err != nil {
+			return nil, err
+		}
+		assets = append(assets, a)
+	}
+	return assets, nil
+}
@@ -0,0 +1,319 @@
+package asset
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/canonicaljson"
+)
+
+// Two-phase transfer
+//
+// TransferAssetOwnership hands the asset over at once, the recipient has no say. With the
+// two-phase transfer the owner offers the asset (ProposeTransfer), and it only changes
+// hands once the recipient agrees (AcceptTransfer); the recipient can also say no
+// (RejectTransfer). Until then the offer is a PendingTransfer stored next to the asset.
+//
+// The parties are client identities (see owner.go): only the owner of the asset may offer it,
+// and only the client that registered the display name of the recipient may accept or reject the offer.
+
+// TransferDocType is the value stored in PendingTransfer.DocType
+const TransferDocType = "transfer"
+
+// PendingTransferKey is the object type of the composite key of a pending transfer.
+// Being a composite key, it is left out of GetStateByRange, so QueryAllAssets never sees it.
+const PendingTransferKey = "transfer"
+
+// PendingTransfer is the offer of an asset from its owner (From) to a recipient (To), both by
+// display name and by client identity. ProposedAt and ExpiresAt are RFC3339 times (UTC).
+// An offer that is not accepted before ExpiresAt can't be accepted anymore, and the owner
+// may then make a new one. An offer only stands while From owns the asset: the chaincodes drop it
+// when the asset changes owner or is deleted (see DropPendingTransfer).
+type PendingTransfer struct {
+	DocType    string `json:"docType"`
+	AssetID    string `json:"assetId"`
+	From       string `json:"from"`
+	FromMSPID  string `json:"fromMspId"`
+	FromID     string `json:"fromId"`
+	To         string `json:"to"`
+	ToMSPID    string `json:"toMspId"`
+	ToID       string `json:"toId"`
+	ProposedAt string `json:"proposedAt"`
+	ExpiresAt  string `json:"expiresAt"`
+}
+
+// ProposeTransfer offers the asset to the client that registered the display name "to" among the
+// display names of toMSPID, for validFor (a Go duration like "24h").
+// Only the owner of the asset may do so, and only if it has no pending offer that has not expired yet.
+// An offer of a previous owner does not count, the new offer replaces it.
+func ProposeTransfer(stub shim.ChaincodeStubInterface, assetID string, to string, toMSPID string, validFor string) (*PendingTransfer, error) {
+	duration, err := time.ParseDuration(validFor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid validity %q, expecting a duration like 24h: %v", validFor, err)
+	}
+	if duration <= 0 {
+		return nil, fmt.Errorf("validity must be positive, got %s", validFor)
+	}
+
+	a, err := readAsset(stub, assetID)
+	if err != nil {
+		return nil, err
+	}
+	err = a.CheckNotDeleted()
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.CheckOwner(stub)
+	if err != nil {
+		return nil, err
+	}
+	recipient, err := ResolveDisplayName(stub, to, toMSPID)
+	if err != nil {
+		return nil, err
+	}
+	if recipient.MSPID == a.OwnerMSPID && recipient.ClientID == a.OwnerID {
+		return nil, fmt.Errorf("the asset %s is already owned by the client of %s", assetID, to)
+	}
+
+	now, err := txTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := readPendingTransfer(stub, assetID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.offeredBy(a) {
+		expired, err := existing.expired(now)
+		if err != nil {
+			return nil, err
+		}
+		if !expired {
+			return nil, fmt.Errorf("the asset %s already has a pending transfer to %s until %s", assetID, existing.To, existing.ExpiresAt)
+		}
+	}
+
+	transfer := &PendingTransfer{
+		DocType:    TransferDocType,
+		AssetID:    assetID,
+		From:       a.Owner,
+		FromMSPID:  a.OwnerMSPID,
+		FromID:     a.OwnerID,
+		To:         recipient.Name,
+		ToMSPID:    recipient.MSPID,
+		ToID:       recipient.ClientID,
+		ProposedAt: now.Format(time.RFC3339Nano),
+		ExpiresAt:  now.Add(duration).Format(time.RFC3339Nano),
+	}
+	err = putPendingTransfer(stub, transfer)
+	if err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+// AcceptTransfer makes the recipient of the pending transfer the owner of the asset,
+// and returns the accepted transfer and the asset before and after it.
+// Only the recipient may accept, before the offer expires and while the asset still has the owner that offered it.
+func AcceptTransfer(stub shim.ChaincodeStubInterface, assetID string) (*PendingTransfer, *Asset, *Asset, error) {
+	transfer, err := recipientTransfer(stub, assetID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	now, err := txTime(stub)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	expired, err := transfer.expired(now)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if expired {
+		return nil, nil, nil, fmt.Errorf("the transfer of the asset %s to %s expired at %s", assetID, transfer.To, transfer.ExpiresAt)
+	}
+
+	a, err := readAsset(stub, assetID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	err = a.CheckNotDeleted()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if !transfer.offeredBy(a) {
+		return nil, nil, nil, fmt.Errorf("the asset %s changed owner from %s to %s since the transfer was proposed", assetID, transfer.From, a.Owner)
+	}
+
+	before := *a
+	a.SetOwner(&DisplayName{Name: transfer.To, MSPID: transfer.ToMSPID, ClientID: transfer.ToID})
+	a.Version++
+	a.UpdatedBy = SubmitterID(stub)
+	err = a.Validate()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	assetJSON, err := a.Marshal()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	err = stub.PutState(assetID, assetJSON)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to transfer asset: %v", err)
+	}
+	err = UpdateIndexes(stub, &before, a)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	err = DropPendingTransfer(stub, assetID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return transfer, &before, a, nil
+}
+
+// RejectTransfer drops the pending transfer of the asset and returns it, the asset keeps its owner.
+// Only the recipient may reject, also after the offer expired.
+func RejectTransfer(stub shim.ChaincodeStubInterface, assetID string) (*PendingTransfer, error) {
+	transfer, err := recipientTransfer(stub, assetID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = DropPendingTransfer(stub, assetID)
+	if err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+// QueryPendingTransfer returns the pending transfer of the asset
+func QueryPendingTransfer(stub shim.ChaincodeStubInterface, assetID string) (*PendingTransfer, error) {
+	transfer, err := readPendingTransfer(stub, assetID)
+	if err != nil {
+		return nil, err
+	}
+	if transfer == nil {
+		return nil, fmt.Errorf("the asset %s has no pending transfer", assetID)
+	}
+	return transfer, nil
+}
+
+// recipientTransfer returns the pending transfer of the asset if the client is its recipient
+func recipientTransfer(stub shim.ChaincodeStubInterface, assetID string) (*PendingTransfer, error) {
+	transfer, err := QueryPendingTransfer(stub, assetID)
+	if err != nil {
+		return nil, err
+	}
+
+	mspID, clientID, err := Submitter(stub)
+	if err != nil {
+		return nil, err
+	}
+	if mspID != transfer.ToMSPID || clientID != transfer.ToID {
+		return nil, fmt.Errorf("client %s of %s is not the recipient of the transfer of the asset %s, only %s can answer it", clientID, mspID, assetID, transfer.To)
+	}
+	return transfer, nil
+}
+
+// offeredBy tells if the offer was made by the current owner of the asset a,
+// by display name and by client identity
+func (t *PendingTransfer) offeredBy(a *Asset) bool {
+	return t.From == a.Owner && t.FromMSPID == a.OwnerMSPID && t.FromID == a.OwnerID
+}
+
+// expired tells if the offer can't be accepted anymore at now
+func (t *PendingTransfer) expired(now time.Time) (bool, error) {
+	expiresAt, err := time.Parse(time.RFC3339Nano, t.ExpiresAt)
+	if err != nil {
+		return false, fmt.Errorf("invalid expiry of the transfer of the asset %s: %v", t.AssetID, err)
+	}
+	return !now.Before(expiresAt), nil
+}
+
+// txTime returns the time of the transaction, which is the same on every endorsing peer (unlike time.Now())
+func txTime(stub shim.ChaincodeStubInterface) (time.Time, error) {
+	txTimestamp, err := stub.GetTxTimestamp()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	return txTimestamp.AsTime().UTC(), nil
+}
+
+// readAsset reads the stored asset
+func readAsset(stub shim.ChaincodeStubInterface, assetID string) (*Asset, error) {
+	assetJSON, err := stub.GetState(assetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read asset %s from world state: %v", assetID, err)
+	}
+	if assetJSON == nil {
+		return nil, fmt.Errorf("the asset %s does not exist", assetID)
+	}
+	return Unmarshal(assetJSON)
+}
+
+func pendingTransferKey(stub shim.ChaincodeStubInterface, assetID string) (string, error) {
+	key, err := stub.CreateCompositeKey(PendingTransferKey, []string{assetID})
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s key: %v", PendingTransferKey, err)
+	}
+	return key, nil
+}
+
+// readPendingTransfer returns the pending transfer of the asset, or nil if there is none
+func readPendingTransfer(stub shim.ChaincodeStubInterface, assetID string) (*PendingTransfer, error) {
+	key, err := pendingTransferKey(stub, assetID)
+	if err != nil {
+		return nil, err
+	}
+	transferJSON, err := stub.GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pending transfer of asset %s: %v", assetID, err)
+	}
+	if transferJSON == nil {
+		return nil, nil
+	}
+
+	transfer := new(PendingTransfer)
+	err = json.Unmarshal(transferJSON, transfer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal pending transfer: %v", err)
+	}
+	return transfer, nil
+}
+
+func putPendingTransfer(stub shim.ChaincodeStubInterface, transfer *PendingTransfer) error {
+	key, err := pendingTransferKey(stub, transfer.AssetID)
+	if err != nil {
+		return err
+	}
+	// canonical json, like the assets, so every endorsing peer writes the same bytes
+	transferJSON, err := canonicaljson.Marshal(transfer)
+	if err != nil {
+		return fmt.Errorf("failed to marshal pending transfer: %v", err)
+	}
+	err = stub.PutState(key, transferJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put pending transfer of asset %s: %v", transfer.AssetID, err)
+	}
+	return nil
+}
+
+// DropPendingTransfer deletes the pending transfer of the asset, it does nothing if there is none.
+// The chaincodes call it whenever the asset changes owner or is deleted.
+func DropPendingTransfer(stub shim.ChaincodeStubInterface, assetID string) error {
+	key, err := pendingTransferKey(stub, assetID)
+	if err != nil {
+		return err
+	}
+	err = stub.DelState(key)
+	if err != nil {
+		return fmt.Errorf("failed to delete pending transfer of asset %s: %v", assetID, err)
+	}
+	return nil
+}
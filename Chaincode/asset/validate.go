@@ -0,0 +1,167 @@
+package asset
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// CodeValidationFailed is the code of every ValidationError
+const CodeValidationFailed = "VALIDATION_FAILED"
+
+// Violation is one rule of a `validate` tag that a field does not satisfy
+type Violation struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists every violation found in a document, so the client
+// can fix all of them at once instead of one per transaction.
+// Its Error() is the json of the error, which is what the client receives as
+// the message of the failed transaction.
+type ValidationError struct {
+	Code       string      `json:"code"`
+	Message    string      `json:"message"`
+	Violations []Violation `json:"violations"`
+}
+
+func (e *ValidationError) Error() string {
+	errJSON, err := json.Marshal(e)
+	if err != nil {
+		return e.Message
+	}
+	return string(errJSON)
+}
+
+// ParseValidationError reads back the ValidationError from the message of a failed transaction.
+// It returns false if the message is not a validation error.
+func ParseValidationError(message string) (*ValidationError, bool) {
+	e := new(ValidationError)
+	err := json.Unmarshal([]byte(message), e)
+	if err != nil || e.Code != CodeValidationFailed {
+		return nil, false
+	}
+	return e, true
+}
+
+// validateStruct checks every field of the struct v against the rules of its `validate` tag
+// and returns the violations found, in the order of the fields.
+//
+// The rules of a tag are separated by commas:
+//
+//	required        a string must not be empty
+//	min=N / max=N   bounds of an int, or of the length of a string
+//	enum=a|b|c      the value must be one of a, b or c
+//	pattern=RE      the string must match the regular expression RE (which can't contain a comma)
+//
+// An empty string only gets checked by required, so optional fields can have rules too.
+// The field is named after its json tag in the violations.
+func validateStruct(name string, v interface{}) []Violation {
+	value := reflect.Indirect(reflect.ValueOf(v))
+	t := value.Type()
+
+	var violations []Violation
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get("validate")
+		if tag == "" {
+			continue
+		}
+
+		field := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if field == "" {
+			field = t.Field(i).Name
+		}
+
+		for _, rule := range strings.Split(tag, ",") {
+			message := checkRule(value.Field(i), rule)
+			if message != "" {
+				ruleName := strings.SplitN(rule, "=", 2)[0]
+				violations = append(violations, Violation{
+					Field:   field,
+					Rule:    ruleName,
+					Message: fmt.Sprintf("%s %s %s", name, field, message),
+				})
+			}
+		}
+	}
+	return violations
+}
+
+// checkRule returns why the field breaks the rule, or "" if it doesn't
+func checkRule(field reflect.Value, rule string) string {
+	ruleName, arg := rule, ""
+	if i := strings.Index(rule, "="); i >= 0 {
+		ruleName, arg = rule[:i], rule[i+1:]
+	}
+
+	isString := field.Kind() == reflect.String
+	if isString && field.String() == "" {
+		if ruleName == "required" {
+			return "is required"
+		}
+		return ""
+	}
+
+	switch ruleName {
+	case "required":
+		return ""
+	case "min", "max":
+		bound, err := strconv.Atoi(arg)
+		if err != nil {
+			return fmt.Sprintf("has an invalid %s rule %q", ruleName, arg)
+		}
+		n, unit := 0, ""
+		if isString {
+			n, unit = len(field.String()), " characters long"
+		} else {
+			n = int(field.Int())
+		}
+		if ruleName == "min" && n < bound {
+			return fmt.Sprintf("must be at least %d%s", bound, unit)
+		}
+		if ruleName == "max" && n > bound {
+			return fmt.Sprintf("must be at most %d%s", bound, unit)
+		}
+	case "enum":
+		got := fmt.Sprint(field.Interface())
+		for _, allowed := range strings.Split(arg, "|") {
+			if got == allowed {
+				return ""
+			}
+		}
+		return fmt.Sprintf("must be one of %s, got %q", strings.ReplaceAll(arg, "|", ", "), got)
+	case "pattern":
+		re, err := compilePattern(arg)
+		if err != nil {
+			return fmt.Sprintf("has an invalid pattern rule: %v", err)
+		}
+		if !re.MatchString(field.String()) {
+			return fmt.Sprintf("%q does not match %s", field.String(), arg)
+		}
+	default:
+		return fmt.Sprintf("has an unknown rule %q", ruleName)
+	}
+	return ""
+}
+
+// patterns caches the regular expressions of the pattern rules by their source,
+// so Validate compiles each of them once and not on every call
+var patterns sync.Map
+
+// compilePattern returns the compiled regular expression of a pattern rule
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patterns.Store(pattern, re)
+	return re, nil
+}
@@ -0,0 +1,100 @@
+package asset
+
+import (
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	valid := func() *Asset {
+		return &Asset{ID: "asset1", Owner: "Alice", Color: "red", Size: 5, Price: 100}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(a *Asset)
+		want   []Violation
+	}{
+		{name: "valid", modify: func(a *Asset) {}},
+		{name: "valid with status", modify: func(a *Asset) { a.Status = StatusActive; a.DocType = DocType }},
+		{
+			name:   "missing owner",
+			modify: func(a *Asset) { a.Owner = "" },
+			want:   []Violation{{Field: "owner", Rule: "required", Message: "asset owner is required"}},
+		},
+		{
+			name: "every violation at once",
+			modify: func(a *Asset) {
+				a.ID = "asset 1"
+				a.Color = "pink"
+				a.Size = -1
+				a.Price = 0
+				a.Status = "lost"
+			},
+			want: []Violation{
+				{Field: "ID", Rule: "pattern", Message: `asset ID "asset 1" does not match ^[A-Za-z0-9][A-Za-z0-9_.-]*$`},
+				{Field: "color", Rule: "enum", Message: `asset color must be one of red, blue, green, yellow, black, white, got "pink"`},
+				{Field: "size", Rule: "min", Message: "asset size must be at least 0"},
+				{Field: "price", Rule: "min", Message: "asset price must be at least 1"},
+				{Field: "status", Rule: "enum", Message: `asset status must be one of active, inactive, deleted, got "lost"`},
+			},
+		},
+		{
+			name:   "too long ID and foreign docType",
+			modify: func(a *Asset) { a.ID = string(make([]byte, 65)); a.DocType = "document" },
+			want: []Violation{
+				{Field: "docType", Rule: "enum", Message: `asset docType must be one of asset, got "document"`},
+				{Field: "ID", Rule: "max", Message: "asset ID must be at most 64 characters long"},
+				{Field: "ID", Rule: "pattern"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := valid()
+			tt.modify(a)
+
+			err := a.Validate()
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("Validate() = nil, want an error")
+			}
+
+			validationErr, ok := ParseValidationError(err.Error())
+			if !ok {
+				t.Fatalf("error %q is not a validation error", err)
+			}
+			if len(validationErr.Violations) != len(tt.want) {
+				t.Fatalf("violations = %+v, want %+v", validationErr.Violations, tt.want)
+			}
+			for i, want := range tt.want {
+				got := validationErr.Violations[i]
+				if got.Field != want.Field || got.Rule != want.Rule || (want.Message != "" && got.Message != want.Message) {
+					t.Errorf("violation[%d] = %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCompilePattern(t *testing.T) {
+	first, err := compilePattern("^[a-z]+$")
+	if err != nil {
+		t.Fatalf("compilePattern failed: %v", err)
+	}
+	second, err := compilePattern("^[a-z]+$")
+	if err != nil {
+		t.Fatalf("compilePattern failed: %v", err)
+	}
+	if first != second {
+		t.Error("compilePattern compiled the same pattern twice, want the cached regular expression")
+	}
+	if _, err := compilePattern("[a-z"); err == nil {
+		t.Error("compilePattern of an invalid pattern = nil error, want an error")
+	}
+}
@@ -0,0 +1,118 @@
+package assetevents
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Registry maps the name and the schema version of an event to its Go type
+type Registry struct {
+	types map[string]map[int]func() Event
+}
+
+// NewRegistry creates an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{types: make(map[string]map[int]func() Event)}
+}
+
+// Register registers newEvent, which returns a new empty event, as the type of
+// the events named name with the schema version
+func (r *Registry) Register(name string, version int, newEvent func() Event) error {
+	versions, ok := r.types[name]
+	if !ok {
+		versions = make(map[int]func() Event)
+		r.types[name] = versions
+	}
+	if _, ok := versions[version]; ok {
+		return fmt.Errorf("event %s version %d is already registered", name, version)
+	}
+	versions[version] = newEvent
+	return nil
+}
+
+// Names returns the names of the registered events in alphabetical order
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.types))
+	for name := range r.types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Decode decodes the payload of the event named name into its registered type.
+// It fails if the event or its version is not registered, or if the payload is named differently.
+func (r *Registry) Decode(name string, payload []byte) (Event, error) {
+	versions, ok := r.types[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown event %s, expecting one of %v", name, r.Names())
+	}
+
+	header, err := DecodeHeader(payload)
+	if err != nil {
+		return nil, err
+	}
+	if header.Name != name {
+		return nil, fmt.Errorf("payload of event %s is a %s event", name, header.Name)
+	}
+	newEvent, ok := versions[header.Version]
+	if !ok {
+		return nil, fmt.Errorf("unsupported version %d of event %s", header.Version, name)
+	}
+
+	event := newEvent()
+	err = json.Unmarshal(payload, event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s event: %v", name, err)
+	}
+	return event, nil
+}
+
+// DecodeHeader decodes only the header of an event payload
+func DecodeHeader(payload []byte) (*Header, error) {
+	header := new(Header)
+	err := json.Unmarshal(payload, header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal event header: %v", err)
+	}
+	if header.Name == "" {
+		return nil, fmt.Errorf("event payload has no name, it is not an asset event")
+	}
+	return header, nil
+}
+
+// DefaultRegistry holds the events of this package
+var DefaultRegistry = defaultRegistry()
+
+func defaultRegistry() *Registry {
+	r := NewRegistry()
+	events := map[string]func() Event{
+		NameAssetCreated:     func() Event { return new(AssetCreated) },
+		NameAssetUpdated:     func() Event { return new(AssetUpdated) },
+		NameAssetTransferred: func() Event { return new(AssetTransferred) },
+		NameAssetDeleted:     func() Event { return new(AssetDeleted) },
+		NameTransferProposed: func() Event { return new(TransferProposed) },
+		NameTransferAccepted: func() Event { return new(TransferAccepted) },
+		NameTransferRejected: func() Event { return new(TransferRejected) },
+	}
+	for name, newEvent := range events {
+		err := r.Register(name, Version, newEvent)
+		if err != nil {
+			// only happens if a name above is listed twice
+			panic(err)
+		}
+	}
+	return r
+}
+
+// Decode decodes an event payload with the DefaultRegistry, for example in a listener
+//
+//	event, err := assetevents.Decode(chaincodeEvent.EventName, chaincodeEvent.Payload)
+//	switch e := event.(type) {
+//	case *assetevents.AssetTransferred:
+//		fmt.Printf("%s went from %s to %s\n", e.AssetID, e.From, e.To)
+//	}
+func Decode(name string, payload []byte) (Event, error) {
+	return DefaultRegistry.Decode(name, payload)
+}
@@ -0,0 +1,129 @@
+package assetevents
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/canonicaljson"
+)
+
+// Several events per transaction
+//
+// Fabric keeps a single event per transaction, so a transaction that writes several assets
+// (InitLedger for example) can't emit an event per write. Instead the events are raised on an
+// Accumulator while the transaction runs, and Flush emits them all at the end in one AssetEvents
+// envelope, for example
+//
+//	{"name":"AssetEvents","version":1,"txId":"…","events":[{"name":"AssetCreated",…},{"name":"AssetCreated",…}]}
+//
+// A listener hands every chaincode event to Unpack, which gives back the events of an envelope
+// as well as a single event emitted on its own.
+
+// NameAssetEvents is the name of the envelope event
+const NameAssetEvents = "AssetEvents"
+
+// Envelope is the payload of the AssetEvents event.
+// Events holds the payloads of the events in the order they were raised.
+type Envelope struct {
+	Name    string            `json:"name"`
+	Version int               `json:"version"`
+	TxID    string            `json:"txId"`
+	Events  []json.RawMessage `json:"events"`
+}
+
+// Accumulator collects the events raised during a transaction.
+// It is not safe for concurrent use, every transaction needs its own Accumulator.
+type Accumulator struct {
+	events []Event
+}
+
+// Raise adds an event, it is emitted by Flush
+func (a *Accumulator) Raise(event Event) {
+	a.events = append(a.events, event)
+}
+
+// Events returns the events raised so far
+func (a *Accumulator) Events() []Event {
+	return a.events
+}
+
+// Flush emits the raised events in one AssetEvents envelope and forgets them.
+// It emits nothing if no event was raised, so a transaction that writes no asset keeps no event.
+func (a *Accumulator) Flush(stub shim.ChaincodeStubInterface) error {
+	if len(a.events) == 0 {
+		return nil
+	}
+
+	envelope := &Envelope{
+		Name:    NameAssetEvents,
+		Version: Version,
+		TxID:    stub.GetTxID(),
+		Events:  make([]json.RawMessage, 0, len(a.events)),
+	}
+	for _, event := range a.events {
+		// canonical json, so every endorsing peer emits the same bytes
+		payload, err := canonicaljson.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s event: %v", event.EventHeader().Name, err)
+		}
+		envelope.Events = append(envelope.Events, payload)
+	}
+
+	payload, err := canonicaljson.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %v", NameAssetEvents, err)
+	}
+	err = stub.SetEvent(NameAssetEvents, payload)
+	if err != nil {
+		return fmt.Errorf("failed to emit %s event: %v", NameAssetEvents, err)
+	}
+	a.events = nil
+	return nil
+}
+
+// Unpack decodes a chaincode event with the registry r: the events of an AssetEvents envelope,
+// in the order they were raised, or the single event of any other name
+func (r *Registry) Unpack(name string, payload []byte) ([]Event, error) {
+	if name != NameAssetEvents {
+		event, err := r.Decode(name, payload)
+		if err != nil {
+			return nil, err
+		}
+		return []Event{event}, nil
+	}
+
+	envelope := new(Envelope)
+	err := json.Unmarshal(payload, envelope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s event: %v", NameAssetEvents, err)
+	}
+	if envelope.Version != Version {
+		return nil, fmt.Errorf("unsupported version %d of event %s", envelope.Version, NameAssetEvents)
+	}
+
+	events := make([]Event, 0, len(envelope.Events))
+	for i, eventJSON := range envelope.Events {
+		header, err := DecodeHeader(eventJSON)
+		if err != nil {
+			return nil, fmt.Errorf("event %d of %s: %v", i, NameAssetEvents, err)
+		}
+		event, err := r.Decode(header.Name, eventJSON)
+		if err != nil {
+			return nil, fmt.Errorf("event %d of %s: %v", i, NameAssetEvents, err)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// Unpack decodes a chaincode event with the DefaultRegistry, for example in a listener
+//
+//	events, err := assetevents.Unpack(chaincodeEvent.EventName, chaincodeEvent.Payload)
+//	for _, event := range events {
+//		fmt.Println(event.EventHeader().Name, event.EventHeader().AssetID)
+//	}
+func Unpack(name string, payload []byte) ([]Event, error) {
+	return DefaultRegistry.Unpack(name, payload)
+}
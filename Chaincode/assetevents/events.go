@@ -0,0 +1,223 @@
+// Package assetevents defines the typed events emitted by the SimpleAssetChaincodes
+// and decodes them on the consumer side.
+//
+// Every write of an asset emits one of AssetCreated, AssetUpdated, AssetTransferred or
+// AssetDeleted, and every step of a two-phase transfer one of TransferProposed, TransferAccepted
+// or TransferRejected. The payload is a json document with a Header (name, schema version,
+// transaction ID and time, the client that submitted the transaction and the asset ID)
+// followed by the asset before and after the write, for example
+//
+//	{"name":"AssetTransferred","version":1,"txId":"…","timestamp":"2024-01-31T12:00:00Z",
+//	 "actor":{"mspId":"Org1MSP","id":"…"},"assetId":"asset1","from":"Alice","to":"Bob",
+//	 "before":{…,"owner":"Alice",…},"after":{…,"owner":"Bob",…}}
+//
+// A listener hands the event name and payload to Decode and gets back the typed event,
+// instead of guessing the format of the payload. A transaction that writes several assets
+// raises their events on an Accumulator and emits them in one AssetEvents envelope,
+// which Unpack turns back into the typed events.
+package assetevents
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/asset"
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/canonicaljson"
+)
+
+// Names of the asset events
+const (
+	NameAssetCreated     = "AssetCreated"
+	NameAssetUpdated     = "AssetUpdated"
+	NameAssetTransferred = "AssetTransferred"
+	NameAssetDeleted     = "AssetDeleted"
+
+	NameTransferProposed = "TransferProposed"
+	NameTransferAccepted = "TransferAccepted"
+	NameTransferRejected = "TransferRejected"
+)
+
+// Version is the schema version of the events emitted by this package.
+// It is bumped when a field of an event changes meaning or is removed, adding a field keeps the version.
+const Version = 1
+
+// Event is implemented by every typed event
+type Event interface {
+	EventHeader() *Header
+}
+
+// Actor is the client that submitted the transaction
+type Actor struct {
+	MSPID string `json:"mspId"`
+	ID    string `json:"id"`
+}
+
+// Header holds the fields shared by every event.
+// Timestamp is the time of the transaction in RFC3339 (UTC).
+type Header struct {
+	Name      string `json:"name"`
+	Version   int    `json:"version"`
+	TxID      string `json:"txId"`
+	Timestamp string `json:"timestamp"`
+	Actor     Actor  `json:"actor"`
+	AssetID   string `json:"assetId"`
+}
+
+// EventHeader returns the header, it makes every struct that embeds a Header an Event
+func (h *Header) EventHeader() *Header {
+	return h
+}
+
+// AssetCreated is emitted when an asset is created
+type AssetCreated struct {
+	Header
+	After *asset.Asset `json:"after"`
+}
+
+// AssetUpdated is emitted when the fields of an asset change, without a change of owner.
+// A restore of a soft deleted asset is an update too (its status goes from deleted to active).
+type AssetUpdated struct {
+	Header
+	Before *asset.Asset `json:"before"`
+	After  *asset.Asset `json:"after"`
+}
+
+// AssetTransferred is emitted when an asset changes owner.
+// From and To are the display names of the owners, Before and After tell their client identities.
+type AssetTransferred struct {
+	Header
+	From   string       `json:"from"`
+	To     string       `json:"to"`
+	Before *asset.Asset `json:"before"`
+	After  *asset.Asset `json:"after"`
+}
+
+// AssetDeleted is emitted when an asset is deleted.
+// After is the asset marked as deleted for a soft delete (Soft), and nil when the asset was removed.
+type AssetDeleted struct {
+	Header
+	Soft   bool         `json:"soft"`
+	Before *asset.Asset `json:"before"`
+	After  *asset.Asset `json:"after,omitempty"`
+}
+
+// TransferProposed is emitted when the owner of an asset offers it to a recipient (see asset.ProposeTransfer)
+type TransferProposed struct {
+	Header
+	Transfer *asset.PendingTransfer `json:"transfer"`
+}
+
+// TransferAccepted is emitted when the recipient accepts the offer, together with
+// the AssetTransferred event of the change of owner
+type TransferAccepted struct {
+	Header
+	Transfer *asset.PendingTransfer `json:"transfer"`
+}
+
+// TransferRejected is emitted when the recipient rejects the offer, the asset keeps its owner
+type TransferRejected struct {
+	Header
+	Transfer *asset.PendingTransfer `json:"transfer"`
+}
+
+// NewTransfer returns the event named name (one of the NameTransfer names) of a step of the two-phase transfer
+func NewTransfer(stub shim.ChaincodeStubInterface, name string, transfer *asset.PendingTransfer) (Event, error) {
+	header, err := newHeader(stub, name, transfer.AssetID)
+	if err != nil {
+		return nil, err
+	}
+	switch name {
+	case NameTransferProposed:
+		return &TransferProposed{Header: *header, Transfer: transfer}, nil
+	case NameTransferAccepted:
+		return &TransferAccepted{Header: *header, Transfer: transfer}, nil
+	case NameTransferRejected:
+		return &TransferRejected{Header: *header, Transfer: transfer}, nil
+	default:
+		return nil, fmt.Errorf("unknown transfer event %s", name)
+	}
+}
+
+// NewChange returns the event of the write of an asset from before to after:
+// before is nil for a create, after is nil for a delete (as for asset.UpdateIndexes).
+func NewChange(stub shim.ChaincodeStubInterface, before *asset.Asset, after *asset.Asset) (Event, error) {
+	switch {
+	case before == nil && after == nil:
+		return nil, fmt.Errorf("an asset change needs the asset before or after it")
+	case before == nil:
+		header, err := newHeader(stub, NameAssetCreated, after.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &AssetCreated{Header: *header, After: after}, nil
+	case after == nil || (after.IsDeleted() && !before.IsDeleted()):
+		header, err := newHeader(stub, NameAssetDeleted, before.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &AssetDeleted{Header: *header, Soft: after != nil, Before: before, After: after}, nil
+	case before.OwnerMSPID != after.OwnerMSPID || before.OwnerID != after.OwnerID || before.Owner != after.Owner:
+		header, err := newHeader(stub, NameAssetTransferred, after.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &AssetTransferred{Header: *header, From: before.Owner, To: after.Owner, Before: before, After: after}, nil
+	default:
+		header, err := newHeader(stub, NameAssetUpdated, after.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &AssetUpdated{Header: *header, Before: before, After: after}, nil
+	}
+}
+
+// EmitChange emits the event of the write of an asset from before to after (see NewChange)
+func EmitChange(stub shim.ChaincodeStubInterface, before *asset.Asset, after *asset.Asset) error {
+	event, err := NewChange(stub, before, after)
+	if err != nil {
+		return err
+	}
+	return Emit(stub, event)
+}
+
+// Emit sets the event of the transaction, named after its header.
+// Fabric keeps a single event per transaction: a transaction that emits twice only delivers the last one,
+// a transaction that writes several assets raises their events on an Accumulator instead.
+func Emit(stub shim.ChaincodeStubInterface, event Event) error {
+	name := event.EventHeader().Name
+
+	// canonical json, so every endorsing peer emits the same bytes
+	payload, err := canonicaljson.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %v", name, err)
+	}
+	err = stub.SetEvent(name, payload)
+	if err != nil {
+		return fmt.Errorf("failed to emit %s event: %v", name, err)
+	}
+	return nil
+}
+
+// newHeader fills in the header of an event from the transaction.
+// The actor is left empty when the transaction has no readable creator.
+func newHeader(stub shim.ChaincodeStubInterface, name string, assetID string) (*Header, error) {
+	txTimestamp, err := stub.GetTxTimestamp()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+
+	header := &Header{
+		Name:      name,
+		Version:   Version,
+		TxID:      stub.GetTxID(),
+		Timestamp: txTimestamp.AsTime().UTC().Format(time.RFC3339Nano),
+		AssetID:   assetID,
+	}
+	mspID, clientID, err := asset.Submitter(stub)
+	if err == nil {
+		header.Actor = Actor{MSPID: mspID, ID: clientID}
+	}
+	return header, nil
+}
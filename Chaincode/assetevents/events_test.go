@@ -0,0 +1,261 @@
+package assetevents
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/asset"
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/chaincodetest"
+)
+
+func newStub(t *testing.T) (*chaincodetest.Stub, *chaincodetest.ClientIdentity) {
+	t.Helper()
+	alice, err := chaincodetest.NewClientIdentity("Org1MSP", "Alice", nil)
+	if err != nil {
+		t.Fatalf("failed to create identity: %v", err)
+	}
+	stub := chaincodetest.NewStub("asset", nil)
+	if err := stub.SetCreator(alice); err != nil {
+		t.Fatalf("failed to set creator: %v", err)
+	}
+	return stub, alice
+}
+
+func TestEmitChangeDecodes(t *testing.T) {
+	alice := &asset.Asset{ID: "asset1", Owner: "Alice", OwnerMSPID: "Org1MSP", OwnerID: "alice", Color: "red", Size: 5, Price: 100, Version: 1}
+	updated := *alice
+	updated.Price, updated.Version = 200, 2
+	bob := updated
+	bob.Owner, bob.OwnerID, bob.Version = "Bob", "bob", 3
+	softDeleted := bob
+	softDeleted.Status, softDeleted.Version = asset.StatusDeleted, 4
+
+	tests := []struct {
+		name     string
+		before   *asset.Asset
+		after    *asset.Asset
+		wantName string
+		check    func(t *testing.T, event Event)
+	}{
+		{
+			name: "create", after: alice, wantName: NameAssetCreated,
+			check: func(t *testing.T, event Event) {
+				if e := event.(*AssetCreated); e.After.Owner != "Alice" {
+					t.Errorf("after = %+v, want the asset of Alice", e.After)
+				}
+			},
+		},
+		{
+			name: "update", before: alice, after: &updated, wantName: NameAssetUpdated,
+			check: func(t *testing.T, event Event) {
+				if e := event.(*AssetUpdated); e.Before.Price != 100 || e.After.Price != 200 {
+					t.Errorf("event = %+v, want price 100 before and 200 after", e)
+				}
+			},
+		},
+		{
+			name: "transfer", before: &updated, after: &bob, wantName: NameAssetTransferred,
+			check: func(t *testing.T, event Event) {
+				if e := event.(*AssetTransferred); e.From != "Alice" || e.To != "Bob" || e.After.OwnerID != "bob" {
+					t.Errorf("event = %+v, want a transfer from Alice to Bob", e)
+				}
+			},
+		},
+		{
+			name: "soft delete", before: &bob, after: &softDeleted, wantName: NameAssetDeleted,
+			check: func(t *testing.T, event Event) {
+				if e := event.(*AssetDeleted); !e.Soft || e.After == nil {
+					t.Errorf("event = %+v, want a soft delete with the deleted asset", e)
+				}
+			},
+		},
+		{
+			name: "delete", before: &bob, wantName: NameAssetDeleted,
+			check: func(t *testing.T, event Event) {
+				if e := event.(*AssetDeleted); e.Soft || e.After != nil || e.Before.Owner != "Bob" {
+					t.Errorf("event = %+v, want a delete of the asset of Bob", e)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub, client := newStub(t)
+			stub.MockTransactionStart("tx1")
+			stub.TxTimestamp = timestamppb.New(time.Date(2024, time.January, 31, 12, 0, 0, 0, time.UTC))
+			err := EmitChange(stub, tt.before, tt.after)
+			stub.MockTransactionEnd("tx1")
+			if err != nil {
+				t.Fatalf("EmitChange failed: %v", err)
+			}
+
+			emitted := <-stub.ChaincodeEventsChannel
+			if emitted.EventName != tt.wantName {
+				t.Fatalf("event name = %s, want %s", emitted.EventName, tt.wantName)
+			}
+			event, err := Decode(emitted.EventName, emitted.Payload)
+			if err != nil {
+				t.Fatalf("Decode failed: %v", err)
+			}
+
+			header := event.EventHeader()
+			want := Header{
+				Name:      tt.wantName,
+				Version:   Version,
+				TxID:      "tx1",
+				Timestamp: "2024-01-31T12:00:00Z",
+				Actor:     Actor{MSPID: "Org1MSP", ID: client.ID},
+				AssetID:   "asset1",
+			}
+			if *header != want {
+				t.Errorf("header = %+v, want %+v", *header, want)
+			}
+			tt.check(t, event)
+		})
+	}
+}
+
+func TestNewTransferDecodes(t *testing.T) {
+	transfer := &asset.PendingTransfer{DocType: asset.TransferDocType, AssetID: "asset1", From: "Alice", To: "Bob", ExpiresAt: "2024-02-01T12:00:00Z"}
+
+	for _, name := range []string{NameTransferProposed, NameTransferAccepted, NameTransferRejected} {
+		t.Run(name, func(t *testing.T) {
+			stub, _ := newStub(t)
+			stub.MockTransactionStart("tx1")
+			event, err := NewTransfer(stub, name, transfer)
+			if err == nil {
+				err = Emit(stub, event)
+			}
+			stub.MockTransactionEnd("tx1")
+			if err != nil {
+				t.Fatalf("NewTransfer failed: %v", err)
+			}
+
+			emitted := <-stub.ChaincodeEventsChannel
+			decoded, err := Decode(emitted.EventName, emitted.Payload)
+			if err != nil {
+				t.Fatalf("Decode failed: %v", err)
+			}
+			var got *asset.PendingTransfer
+			switch e := decoded.(type) {
+			case *TransferProposed:
+				got = e.Transfer
+			case *TransferAccepted:
+				got = e.Transfer
+			case *TransferRejected:
+				got = e.Transfer
+			}
+			if decoded.EventHeader().Name != name || decoded.EventHeader().AssetID != "asset1" || got == nil || *got != *transfer {
+				t.Errorf("decoded %T %+v, want the %s event of the transfer %+v", decoded, decoded, name, transfer)
+			}
+		})
+	}
+
+	stub, _ := newStub(t)
+	if _, err := NewTransfer(stub, NameAssetTransferred, transfer); err == nil {
+		t.Errorf("NewTransfer(%s) succeeded, want an error", NameAssetTransferred)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		eventName   string
+		payload     string
+		wantMessage string
+	}{
+		{"unknown event", "AssetBurnt", `{"name":"AssetBurnt","version":1}`, "unknown event AssetBurnt"},
+		{"unsupported version", NameAssetCreated, `{"name":"AssetCreated","version":2}`, "unsupported version 2 of event AssetCreated"},
+		{"other event", NameAssetCreated, `{"name":"AssetDeleted","version":1}`, "payload of event AssetCreated is a AssetDeleted event"},
+		{"bare payload", NameAssetCreated, `asset1`, "failed to unmarshal event header"},
+		{"no name", NameAssetCreated, `{"assetId":"asset1"}`, "it is not an asset event"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(tt.eventName, []byte(tt.payload))
+			if err == nil || !strings.Contains(err.Error(), tt.wantMessage) {
+				t.Errorf("Decode = %v, want an error containing %q", err, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestAccumulatorUnpack(t *testing.T) {
+	stub, _ := newStub(t)
+	stub.MockTransactionStart("batch")
+	stub.TxTimestamp = timestamppb.New(time.Date(2024, time.January, 31, 12, 0, 0, 0, time.UTC))
+	defer stub.MockTransactionEnd("batch")
+
+	events := new(Accumulator)
+	if err := events.Flush(stub); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	select {
+	case e := <-stub.ChaincodeEventsChannel:
+		t.Fatalf("Flush without events emitted %s", e.EventName)
+	default:
+	}
+
+	asset1 := &asset.Asset{ID: "asset1", Owner: "Alice", Color: "red", Size: 5, Price: 100, Version: 1}
+	asset2 := &asset.Asset{ID: "asset2", Owner: "Alice", Color: "blue", Size: 10, Price: 200, Version: 1}
+	for _, a := range []*asset.Asset{asset1, asset2} {
+		event, err := NewChange(stub, nil, a)
+		if err != nil {
+			t.Fatalf("NewChange failed: %v", err)
+		}
+		events.Raise(event)
+	}
+	if err := events.Flush(stub); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if len(events.Events()) != 0 {
+		t.Errorf("Flush kept %d events, want none", len(events.Events()))
+	}
+
+	emitted := <-stub.ChaincodeEventsChannel
+	if emitted.EventName != NameAssetEvents {
+		t.Fatalf("event name = %s, want %s", emitted.EventName, NameAssetEvents)
+	}
+	unpacked, err := Unpack(emitted.EventName, emitted.Payload)
+	if err != nil {
+		t.Fatalf("Unpack failed: %v", err)
+	}
+	if len(unpacked) != 2 || unpacked[0].(*AssetCreated).After.ID != "asset1" || unpacked[1].(*AssetCreated).After.ID != "asset2" {
+		t.Errorf("unpacked = %+v, want the creation of asset1 then asset2", unpacked)
+	}
+
+	// a single event emitted on its own unpacks too
+	if err := EmitChange(stub, nil, asset1); err != nil {
+		t.Fatalf("EmitChange failed: %v", err)
+	}
+	emitted = <-stub.ChaincodeEventsChannel
+	unpacked, err = Unpack(emitted.EventName, emitted.Payload)
+	if err != nil || len(unpacked) != 1 || unpacked[0].EventHeader().Name != NameAssetCreated {
+		t.Errorf("Unpack = %+v, %v, want the single AssetCreated event", unpacked, err)
+	}
+}
+
+func TestUnpackErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		payload     string
+		wantMessage string
+	}{
+		{"bare payload", `asset1`, "failed to unmarshal AssetEvents event"},
+		{"unsupported version", `{"name":"AssetEvents","version":2,"events":[]}`, "unsupported version 2 of event AssetEvents"},
+		{"unknown event", `{"name":"AssetEvents","version":1,"events":[{"name":"AssetBurnt","version":1}]}`, "event 0 of AssetEvents: unknown event AssetBurnt"},
+		{"no name", `{"name":"AssetEvents","version":1,"events":[{"assetId":"asset1"}]}`, "event 0 of AssetEvents: event payload has no name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Unpack(NameAssetEvents, []byte(tt.payload))
+			if err == nil || !strings.Contains(err.Error(), tt.wantMessage) {
+				t.Errorf("Unpack = %v, want an error containing %q", err, tt.wantMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+// Package canonicaljson encodes values into canonical json: the same value always
+// gives the same bytes.
+//
+// Every endorsing peer runs the chaincode on its own and the write sets of their
+// endorsements must be byte for byte identical, otherwise the transaction is rejected.
+// json.Marshal is deterministic for a given struct, but the bytes still depend on
+// the order of the struct fields, on how a number was written (5, 5.0 or 5e0 once it
+// went through a json.Number or a float) and on HTML escaping. Marshal removes all of these:
+//
+//   - object keys are sorted (by their UTF-8 bytes), whether they come from a struct or a map
+//   - numbers without fraction are written as integers, other numbers in their shortest form
+//   - no whitespace and no HTML escaping of <, > and &
+package canonicaljson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// Marshal returns the canonical json encoding of v.
+// v is first encoded with encoding/json, so the json tags (and MarshalJSON methods) are respected.
+func Marshal(v interface{}) ([]byte, error) {
+	var plain bytes.Buffer
+	enc := json.NewEncoder(&plain)
+	enc.SetEscapeHTML(false)
+	err := enc.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(&plain)
+	dec.UseNumber()
+	var value interface{}
+	err = dec.Decode(&value)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	err = encode(&buf, value)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// encode writes value, as decoded by a json.Decoder using UseNumber, in canonical form
+func encode(buf *bytes.Buffer, value interface{}) error {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		buf.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			encodeString(buf, key)
+			buf.WriteByte(':')
+			err := encode(buf, v[key])
+			if err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, element := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			err := encode(buf, element)
+			if err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case json.Number:
+		n, err := NormalizeNumber(v)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(string(n))
+	case string:
+		encodeString(buf, v)
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case nil:
+		buf.WriteString("null")
+	default:
+		return fmt.Errorf("unexpected json value of type %T", value)
+	}
+	return nil
+}
+
+// encodeString writes s as a json string without HTML escaping
+func encodeString(buf *bytes.Buffer, s string) {
+	var quoted bytes.Buffer
+	enc := json.NewEncoder(&quoted)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s) // encoding a string never fails
+	buf.Write(bytes.TrimRight(quoted.Bytes(), "\n"))
+}
+
+// NormalizeNumber rewrites a number without fraction as an integer (5.0 -> 5, 1e3 -> 1000)
+// and any other number in its shortest form (1.50 -> 1.5)
+func NormalizeNumber(n json.Number) (json.Number, error) {
+	if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+		return json.Number(strconv.FormatInt(i, 10)), nil
+	}
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid number %s: %v", n, err)
+	}
+	if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+		return json.Number(strconv.FormatInt(int64(f), 10)), nil
+	}
+	return json.Number(strconv.FormatFloat(f, 'g', -1, 64)), nil
+}
@@ -0,0 +1,82 @@
+package canonicaljson
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+type attributes struct {
+	Weight  float64           `json:"weight"`
+	Name    string            `json:"name"`
+	Labels  map[string]string `json:"labels"`
+	Counts  []int             `json:"counts"`
+	Comment string            `json:"comment,omitempty"`
+}
+
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{
+			name:  "struct fields sorted",
+			value: attributes{Weight: 2.5, Name: "a<b>&c", Labels: map[string]string{"z": "1", "a": "2"}, Counts: []int{3, 1}},
+			want:  `{"counts":[3,1],"labels":{"a":"2","z":"1"},"name":"a<b>&c","weight":2.5}`,
+		},
+		{
+			name:  "numbers normalized",
+			value: map[string]interface{}{"b": json.Number("5.0"), "a": json.Number("1e3"), "c": 1.50, "d": json.Number("-0.25")},
+			want:  `{"a":1000,"b":5,"c":1.5,"d":-0.25}`,
+		},
+		{
+			name:  "nested maps and nulls",
+			value: map[string]interface{}{"outer": map[string]interface{}{"y": nil, "x": []interface{}{true, "s"}}},
+			want:  `{"outer":{"x":[true,"s"],"y":null}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Marshal(tt.value)
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Marshal = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestMarshalDeterministic encodes maps that Go iterates in a random order many times
+// and checks every run gives the same bytes, as every endorsing peer must
+func TestMarshalDeterministic(t *testing.T) {
+	labels := make(map[string]string)
+	for i := 0; i < 50; i++ {
+		labels[fmt.Sprintf("label%d", i)] = fmt.Sprint(i)
+	}
+	value := attributes{Weight: 1e2, Name: "asset", Labels: labels}
+
+	first, err := Marshal(value)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	for i := 0; i < 100; i++ {
+		// a fresh map each run, so the iteration order differs
+		copied := make(map[string]string, len(labels))
+		for k, v := range labels {
+			copied[k] = v
+		}
+		value.Labels = copied
+
+		got, err := Marshal(value)
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		if string(got) != string(first) {
+			t.Fatalf("run %d gave %s, want %s", i, got, first)
+		}
+	}
+}
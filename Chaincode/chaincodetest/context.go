@@ -0,0 +1,60 @@
+package chaincodetest
+
+import (
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// TransactionContext is a contractapi.TransactionContextInterface for calling
+// contract functions directly, without going through contractapi.NewChaincode.
+// Its world state is the in-memory Stub and its client is ClientIdentity,
+// which is also the creator of the Stub, so code that reads the identity with
+// cid.New(stub) sees the same client. Change the client with SetClientIdentity.
+type TransactionContext struct {
+	Stub           *Stub
+	ClientIdentity *ClientIdentity
+}
+
+// NewTransactionContext creates a context with an empty world state, the given
+// client and a started transaction "tx0"
+func NewTransactionContext(ci *ClientIdentity) *TransactionContext {
+	ctx := &TransactionContext{
+		Stub: NewStub("chaincodetest", nil),
+	}
+	ctx.SetClientIdentity(ci)
+	ctx.StartTransaction("tx0")
+	return ctx
+}
+
+// SetClientIdentity makes ci the client of the next calls, both for GetClientIdentity
+// and for the creator of the stub
+func (ctx *TransactionContext) SetClientIdentity(ci *ClientIdentity) {
+	ctx.ClientIdentity = ci
+	ctx.Stub.Creator = nil
+	if ci != nil && ci.Certificate != nil {
+		// SetCreator only fails for a client without a certificate
+		if err := ctx.Stub.SetCreator(ci); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// StartTransaction ends the current transaction and starts a new one with the given ID,
+// so that the writes that follow are recorded under txID
+func (ctx *TransactionContext) StartTransaction(txID string) {
+	ctx.Stub.MockTransactionEnd(ctx.Stub.TxID)
+	ctx.Stub.startTransaction(txID)
+}
+
+// GetStub returns the in-memory stub
+func (ctx *TransactionContext) GetStub() shim.ChaincodeStubInterface {
+	return ctx.Stub
+}
+
+// GetClientIdentity returns the client set for the test
+func (ctx *TransactionContext) GetClientIdentity() cid.ClientIdentity {
+	if ctx.ClientIdentity == nil {
+		return nil
+	}
+	return ctx.ClientIdentity
+}
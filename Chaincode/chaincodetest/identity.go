@@ -0,0 +1,173 @@
+package chaincodetest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-chaincode-go/pkg/attrmgr"
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-protos-go/msp"
+)
+
+// ClientIdentity is a cid.ClientIdentity whose MSP ID, ID, attributes and
+// certificate are set by the test.
+type ClientIdentity struct {
+	MSPID       string
+	ID          string
+	Attributes  map[string]string
+	Certificate *x509.Certificate
+}
+
+// NewClientIdentity creates a ClientIdentity for a user of mspID with the given attributes
+// and organizational units. It is backed by a freshly generated certificate, and its ID is
+// the one the real cid library computes for that certificate.
+func NewClientIdentity(mspID string, commonName string, attrs map[string]string, organizationalUnits ...string) (*ClientIdentity, error) {
+	cert, err := NewCertificate(commonName, organizationalUnits, attrs)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := CertificateID(mspID, cert)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes := make(map[string]string, len(attrs))
+	for name, value := range attrs {
+		attributes[name] = value
+	}
+
+	return &ClientIdentity{
+		MSPID:       mspID,
+		ID:          id,
+		Attributes:  attributes,
+		Certificate: cert,
+	}, nil
+}
+
+// GetID returns the ID of the client
+func (ci *ClientIdentity) GetID() (string, error) {
+	if ci.ID == "" {
+		return "", fmt.Errorf("cannot determine identity")
+	}
+	return ci.ID, nil
+}
+
+// GetMSPID returns the MSP ID of the client
+func (ci *ClientIdentity) GetMSPID() (string, error) {
+	return ci.MSPID, nil
+}
+
+// GetAttributeValue returns the value of the attribute attrName of the client
+func (ci *ClientIdentity) GetAttributeValue(attrName string) (string, bool, error) {
+	value, found := ci.Attributes[attrName]
+	return value, found, nil
+}
+
+// AssertAttributeValue fails unless the client has attrName set to attrValue
+func (ci *ClientIdentity) AssertAttributeValue(attrName, attrValue string) error {
+	value, found, err := ci.GetAttributeValue(attrName)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("attribute '%s' was not found", attrName)
+	}
+	if value != attrValue {
+		return fmt.Errorf("attribute '%s' equals '%s', not '%s'", attrName, value, attrValue)
+	}
+	return nil
+}
+
+// GetX509Certificate returns the certificate of the client
+func (ci *ClientIdentity) GetX509Certificate() (*x509.Certificate, error) {
+	return ci.Certificate, nil
+}
+
+// NewCertificate generates a self signed certificate for commonName.
+// The attributes are stored in the certificate the same way the Fabric CA does,
+// so cid.GetAttributeValue can read them back.
+func NewCertificate(commonName string, organizationalUnits []string, attrs map[string]string) (*x509.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject: pkix.Name{
+			CommonName:         commonName,
+			OrganizationalUnit: organizationalUnits,
+		},
+		NotBefore: time.Now().Add(-time.Hour),
+		NotAfter:  time.Now().Add(24 * time.Hour),
+		KeyUsage:  x509.KeyUsageDigitalSignature,
+	}
+
+	if len(attrs) > 0 {
+		attrsJSON, err := json.Marshal(&attrmgr.Attributes{Attrs: attrs})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal attributes: %v", err)
+		}
+		template.ExtraExtensions = append(template.ExtraExtensions, pkix.Extension{
+			Id:    attrmgr.AttrOID,
+			Value: attrsJSON,
+		})
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create certificate: %v", err)
+	}
+	return x509.ParseCertificate(der)
+}
+
+// SerializedIdentity returns the creator bytes of a client of mspID holding cert,
+// as the peer hands them to the chaincode through GetCreator()
+func SerializedIdentity(mspID string, cert *x509.Certificate) ([]byte, error) {
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	return proto.Marshal(&msp.SerializedIdentity{Mspid: mspID, IdBytes: certPEM})
+}
+
+// CertificateID returns the ID that cid gives to a client of mspID holding cert
+func CertificateID(mspID string, cert *x509.Certificate) (string, error) {
+	creator, err := SerializedIdentity(mspID, cert)
+	if err != nil {
+		return "", err
+	}
+	ci, err := cid.New(creatorStub(creator))
+	if err != nil {
+		return "", err
+	}
+	return ci.GetID()
+}
+
+// creatorStub is the bare minimum cid needs to read an identity
+type creatorStub []byte
+
+func (c creatorStub) GetCreator() ([]byte, error) {
+	return c, nil
+}
+
+// SetCreator makes the client identified by ci the creator of the next transactions,
+// so chaincodes that build the identity with cid.New (e.g. through contractapi) see it.
+func (s *Stub) SetCreator(ci *ClientIdentity) error {
+	if ci.Certificate == nil {
+		return fmt.Errorf("client identity %s has no certificate", ci.ID)
+	}
+	creator, err := SerializedIdentity(ci.MSPID, ci.Certificate)
+	if err != nil {
+		return err
+	}
+	s.Creator = creator
+	return nil
+}
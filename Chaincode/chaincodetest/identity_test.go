@@ -0,0 +1,82 @@
+package chaincodetest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+)
+
+func TestClientIdentityMatchesCid(t *testing.T) {
+	ci, err := NewClientIdentity("Org1MSP", "alice", map[string]string{"role": "admin", "department": "IT"})
+	if err != nil {
+		t.Fatalf("failed to create client identity: %v", err)
+	}
+
+	stub := NewStub("identity", nil)
+	if err := stub.SetCreator(ci); err != nil {
+		t.Fatalf("failed to set creator: %v", err)
+	}
+	real, err := cid.New(stub)
+	if err != nil {
+		t.Fatalf("cid could not read the creator: %v", err)
+	}
+
+	for _, got := range []cid.ClientIdentity{ci, real} {
+		id, err := got.GetID()
+		if err != nil || id != ci.ID {
+			t.Errorf("GetID() = %q, %v, want %q", id, err, ci.ID)
+		}
+		mspID, _ := got.GetMSPID()
+		if mspID != "Org1MSP" {
+			t.Errorf("GetMSPID() = %q, want Org1MSP", mspID)
+		}
+		role, found, _ := got.GetAttributeValue("role")
+		if !found || role != "admin" {
+			t.Errorf("GetAttributeValue(role) = %q, %v, want admin", role, found)
+		}
+		if err := got.AssertAttributeValue("department", "IT"); err != nil {
+			t.Errorf("AssertAttributeValue(department, IT) = %v", err)
+		}
+		if err := got.AssertAttributeValue("department", "HR"); err == nil {
+			t.Error("AssertAttributeValue(department, HR) succeeded")
+		}
+		cert, _ := got.GetX509Certificate()
+		if cert == nil || cert.Subject.CommonName != "alice" {
+			t.Errorf("GetX509Certificate() = %v, want the certificate of alice", cert)
+		}
+	}
+}
+
+func TestTransactionContextCreator(t *testing.T) {
+	ci, err := NewClientIdentity("Org1MSP", "alice", map[string]string{"role": "admin"}, "client", "auditor")
+	if err != nil {
+		t.Fatalf("failed to create client identity: %v", err)
+	}
+	bob, err := NewClientIdentity("Org2MSP", "bob", nil)
+	if err != nil {
+		t.Fatalf("failed to create client identity: %v", err)
+	}
+
+	ctx := NewTransactionContext(ci)
+	for _, want := range []*ClientIdentity{ci, bob} {
+		ctx.SetClientIdentity(want)
+
+		// the identity read from the stub is the one of the context
+		real, err := cid.New(ctx.GetStub())
+		if err != nil {
+			t.Fatalf("cid could not read the creator: %v", err)
+		}
+		id, _ := real.GetID()
+		if ctxID, _ := ctx.GetClientIdentity().GetID(); id != want.ID || ctxID != want.ID {
+			t.Errorf("cid.New(stub).GetID() = %q, GetClientIdentity().GetID() = %q, want %q", id, ctxID, want.ID)
+		}
+		cert, _ := real.GetX509Certificate()
+		if got, wantOUs := fmt.Sprint(cert.Subject.OrganizationalUnit), fmt.Sprint(want.Certificate.Subject.OrganizationalUnit); got != wantOUs {
+			t.Errorf("OUs = %s, want %s", got, wantOUs)
+		}
+	}
+	if ous := ci.Certificate.Subject.OrganizationalUnit; fmt.Sprint(ous) != "[client auditor]" {
+		t.Errorf("OUs of alice = %v, want [client auditor]", ous)
+	}
+}
@@ -0,0 +1,171 @@
+package chaincodetest
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/mango"
+)
+
+// compositeKeyNamespace is the first byte of every composite key
+const compositeKeyNamespace = "\x00"
+
+// GetStateByRange returns the simple keys from startKey (included) to endKey (excluded).
+// Like on a peer, and unlike in MockStub, composite keys are never part of a range,
+// so that the indexes of a chaincode do not show up among its documents.
+func (s *Stub) GetStateByRange(startKey, endKey string) (shim.StateQueryIteratorInterface, error) {
+	err := validateSimpleKeys(startKey, endKey)
+	if err != nil {
+		return nil, err
+	}
+	return &stateIterator{entries: s.rangeEntries(startKey, endKey)}, nil
+}
+
+// GetStateByRangeWithPagination returns at most pageSize simple keys of the range,
+// starting at bookmark when it is set.
+// Like on a LevelDB peer, the bookmark returned is the first key of the next page,
+// or empty if this is the last page.
+func (s *Stub) GetStateByRangeWithPagination(startKey, endKey string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *pb.QueryResponseMetadata, error) {
+	err := validateSimpleKeys(startKey, endKey, bookmark)
+	if err != nil {
+		return nil, nil, err
+	}
+	if bookmark != "" {
+		startKey = bookmark
+	}
+
+	entries := s.rangeEntries(startKey, endKey)
+	iterator, metadata := paginate(entries, pageSize)
+	return iterator, metadata, nil
+}
+
+// GetQueryResult runs the Mango query over the json documents of the world state,
+// the way CouchDB would on a peer (see package mango)
+func (s *Stub) GetQueryResult(query string) (shim.StateQueryIteratorInterface, error) {
+	entries, err := s.queryEntries(query)
+	if err != nil {
+		return nil, err
+	}
+	return &stateIterator{entries: entries}, nil
+}
+
+// GetQueryResultWithPagination runs the Mango query and returns at most pageSize
+// of its results, starting at bookmark.
+// Like a CouchDB bookmark, the bookmark returned is opaque: pass it back as it is to get the next page.
+// It is empty on the last page.
+func (s *Stub) GetQueryResultWithPagination(query string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *pb.QueryResponseMetadata, error) {
+	entries, err := s.queryEntries(query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	offset := 0
+	if bookmark != "" {
+		offset, err = strconv.Atoi(strings.TrimPrefix(bookmark, queryBookmarkPrefix))
+		if err != nil || !strings.HasPrefix(bookmark, queryBookmarkPrefix) || offset > len(entries) {
+			return nil, nil, fmt.Errorf("invalid bookmark %s", bookmark)
+		}
+	}
+
+	iterator, metadata := paginate(entries[offset:], pageSize)
+	if metadata.Bookmark != "" {
+		metadata.Bookmark = queryBookmarkPrefix + strconv.Itoa(offset+int(metadata.FetchedRecordsCount))
+	}
+	return iterator, metadata, nil
+}
+
+// queryBookmarkPrefix starts the bookmarks of GetQueryResultWithPagination,
+// so they can't be mistaken for keys
+const queryBookmarkPrefix = "query-offset-"
+
+// queryEntries returns the simple keys whose document matches the query
+func (s *Stub) queryEntries(query string) ([]*queryresult.KV, error) {
+	q, err := mango.ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := s.rangeEntries("", "")
+	docs := make([]mango.Document, 0, len(entries))
+	for _, entry := range entries {
+		docs = append(docs, mango.Document{Key: entry.Key, Value: entry.Value})
+	}
+
+	docs, err = q.Run(docs)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*queryresult.KV, 0, len(docs))
+	for _, doc := range docs {
+		results = append(results, &queryresult.KV{Key: doc.Key, Value: doc.Value})
+	}
+	return results, nil
+}
+
+// rangeEntries returns the simple keys from startKey (included) to endKey (excluded)
+// in key order, an empty endKey means no end
+func (s *Stub) rangeEntries(startKey, endKey string) []*queryresult.KV {
+	var entries []*queryresult.KV
+	for key, value := range s.State {
+		if strings.HasPrefix(key, compositeKeyNamespace) || key < startKey || (endKey != "" && key >= endKey) {
+			continue
+		}
+		entries = append(entries, &queryresult.KV{Key: key, Value: value})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Key < entries[j].Key
+	})
+	return entries
+}
+
+// paginate keeps the first pageSize entries, the bookmark is the key of the next one
+func paginate(entries []*queryresult.KV, pageSize int32) (*stateIterator, *pb.QueryResponseMetadata) {
+	bookmark := ""
+	if pageSize > 0 && len(entries) > int(pageSize) {
+		bookmark = entries[pageSize].Key
+		entries = entries[:pageSize]
+	}
+	return &stateIterator{entries: entries}, &pb.QueryResponseMetadata{
+		FetchedRecordsCount: int32(len(entries)),
+		Bookmark:            bookmark,
+	}
+}
+
+func validateSimpleKeys(keys ...string) error {
+	for _, key := range keys {
+		if strings.HasPrefix(key, compositeKeyNamespace) {
+			return fmt.Errorf("first character of the key [%s] contains a null character which is not allowed", key)
+		}
+	}
+	return nil
+}
+
+// stateIterator walks over the result of a query
+type stateIterator struct {
+	entries []*queryresult.KV
+	next    int
+}
+
+func (it *stateIterator) HasNext() bool {
+	return it.next < len(it.entries)
+}
+
+func (it *stateIterator) Next() (*queryresult.KV, error) {
+	if !it.HasNext() {
+		return nil, fmt.Errorf("no more results")
+	}
+	entry := it.entries[it.next]
+	it.next++
+	return entry, nil
+}
+
+func (it *stateIterator) Close() error {
+	return nil
+}
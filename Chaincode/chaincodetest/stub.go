@@ -0,0 +1,162 @@
+// Package chaincodetest provides test doubles to run the chaincodes of this repo
+// offline, without a peer.
+//
+// Stub is a shimtest.MockStub that also remembers the history of every key and
+// implements the paginated range query and the rich (Mango) queries, so that functions
+// built on GetHistoryForKey, GetStateByRangeWithPagination and GetQueryResult can be tested as well.
+// TransactionContext and ClientIdentity let contractapi contract functions be
+// called directly with a client chosen by the test (MSP ID, ID, attributes, certificate).
+package chaincodetest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Stub is a shimtest.MockStub with history, pagination and rich query support.
+//
+// Invoke the chaincode through Stub.MockInvoke and not through the embedded
+// MockStub, otherwise the chaincode is handed the MockStub and the history
+// is not recorded.
+type Stub struct {
+	*shimtest.MockStub
+
+	// Clock, when set, gives the timestamp of every transaction started after it is set.
+	// By default a transaction gets the current time, like in MockStub.
+	Clock func() time.Time
+
+	cc      shim.Chaincode
+	args    [][]byte
+	history map[string][]*queryresult.KeyModification
+}
+
+// NewStub creates a Stub that invokes cc
+func NewStub(name string, cc shim.Chaincode) *Stub {
+	return &Stub{
+		MockStub: shimtest.NewMockStub(name, cc),
+		cc:       cc,
+		history:  make(map[string][]*queryresult.KeyModification),
+	}
+}
+
+// MockInit calls the Init function of the chaincode in a transaction with the given ID
+func (s *Stub) MockInit(txID string, args [][]byte) pb.Response {
+	s.args = args
+	s.startTransaction(txID)
+	defer s.MockTransactionEnd(txID)
+	return s.cc.Init(s)
+}
+
+// MockInvoke calls the Invoke function of the chaincode in a transaction with the given ID
+func (s *Stub) MockInvoke(txID string, args [][]byte) pb.Response {
+	s.args = args
+	s.startTransaction(txID)
+	defer s.MockTransactionEnd(txID)
+	return s.cc.Invoke(s)
+}
+
+// startTransaction starts the transaction txID at the time given by Clock
+func (s *Stub) startTransaction(txID string) {
+	s.MockTransactionStart(txID)
+	if s.Clock != nil {
+		s.TxTimestamp = timestamppb.New(s.Clock())
+	}
+}
+
+// GetArgs returns the arguments of the current MockInit / MockInvoke
+func (s *Stub) GetArgs() [][]byte {
+	return s.args
+}
+
+// GetStringArgs returns the arguments of the current MockInit / MockInvoke as strings
+func (s *Stub) GetStringArgs() []string {
+	strargs := make([]string, 0, len(s.args))
+	for _, arg := range s.args {
+		strargs = append(strargs, string(arg))
+	}
+	return strargs
+}
+
+// GetFunctionAndParameters splits the arguments into the function name and its parameters
+func (s *Stub) GetFunctionAndParameters() (string, []string) {
+	allargs := s.GetStringArgs()
+	if len(allargs) == 0 {
+		return "", []string{}
+	}
+	return allargs[0], allargs[1:]
+}
+
+// SetEvent sends the event to ChaincodeEventsChannel like MockStub, with the ID of the
+// transaction and the name of the chaincode filled in as in the events delivered by a peer
+func (s *Stub) SetEvent(name string, payload []byte) error {
+	if name == "" {
+		return fmt.Errorf("event name can not be empty string")
+	}
+	s.ChaincodeEventsChannel <- &pb.ChaincodeEvent{TxId: s.TxID, ChaincodeId: s.Name, EventName: name, Payload: payload}
+	return nil
+}
+
+// PutState writes the key and records the write in the history of the key
+func (s *Stub) PutState(key string, value []byte) error {
+	err := s.MockStub.PutState(key, value)
+	if err != nil {
+		return err
+	}
+	s.record(key, value, len(value) == 0)
+	return nil
+}
+
+// DelState deletes the key and records the delete in the history of the key
+func (s *Stub) DelState(key string) error {
+	err := s.MockStub.DelState(key)
+	if err != nil {
+		return err
+	}
+	s.record(key, nil, true)
+	return nil
+}
+
+func (s *Stub) record(key string, value []byte, isDelete bool) {
+	s.history[key] = append(s.history[key], &queryresult.KeyModification{
+		TxId:      s.TxID,
+		Value:     value,
+		Timestamp: s.TxTimestamp,
+		IsDelete:  isDelete,
+	})
+}
+
+// GetHistoryForKey returns every write of the key, newest first like Fabric 2.x does
+func (s *Stub) GetHistoryForKey(key string) (shim.HistoryQueryIteratorInterface, error) {
+	writes := s.history[key]
+	entries := make([]*queryresult.KeyModification, 0, len(writes))
+	for i := len(writes) - 1; i >= 0; i-- {
+		entries = append(entries, writes[i])
+	}
+	return &historyIterator{entries: entries}, nil
+}
+
+// historyIterator walks over the recorded history of a key
+type historyIterator struct {
+	entries []*queryresult.KeyModification
+	next    int
+}
+
+func (it *historyIterator) HasNext() bool {
+	return it.next < len(it.entries)
+}
+
+func (it *historyIterator) Next() (*queryresult.KeyModification, error) {
+	entry := it.entries[it.next]
+	it.next++
+	return entry, nil
+}
+
+func (it *historyIterator) Close() error {
+	return nil
+}
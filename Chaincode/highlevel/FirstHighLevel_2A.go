@@ -0,0 +1,229 @@
+// Package highlevel contains the SimpleAssetChaincode written with the high level contractapi.
+package highlevel
+
+// At the time of release and till hyperledger fabric 2.x,
+// low level APIs are being used.
+// But in the modern world, we now use contractapi which are also called as high level APIs
+// In high level APIs, Init and Invoke functions are not essential
+// However, we can define an Init function and we will invoke this function for instantiation of the 
+// chaincode on a peer after the chaincode definition has been committed to the channel.
+// In low level APIs, we had to use shim.ChaincodeStubInterface, detail code of low level api in chapter 2B
+
+// To read more about contractApi, refer
+// https://pkg.go.dev/github.com/hyperledger/fabric-contract-api-go/contractapi
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/asset"
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/assetevents"
+)
+
+
+
+// You can say that SimpleAssetChaincode acts as the smart contract
+// In other words, SimpleAssetChaincode defines the Smart Contract structure
+// You can add methods to the struct by defining your custom functions
+// So, in that way all your custom functions of the contract can be called using this struct
+// The syntax below indicates that SimpleAssetChaincode inherits all the functions and properties 
+// from contractapi.Contract p
+type SimpleAssetChaincode struct {
+	contractapi.Contract
+}
+
+// TransactionContext is the transaction context of the SimpleAssetChaincode.
+// On top of the stub and the client identity of contractapi.TransactionContext it collects the
+// asset events raised during the transaction: Fabric keeps a single event per transaction, so
+// emitAssetEvents emits them all at the end in one AssetEvents envelope (see package assetevents).
+// contractapi creates a new context for every transaction, so the events of two transactions never mix.
+// The contract functions still take a contractapi.TransactionContextInterface,
+// which TransactionContext implements.
+type TransactionContext struct {
+	contractapi.TransactionContext
+	events assetevents.Accumulator
+}
+
+// AssetEvents returns the events raised so far in the transaction
+func (ctx *TransactionContext) AssetEvents() *assetevents.Accumulator {
+	return &ctx.events
+}
+
+// GetTransactionContextHandler makes contractapi pass a TransactionContext to every contract function
+func (s *SimpleAssetChaincode) GetTransactionContextHandler() contractapi.SettableTransactionContextInterface {
+	return new(TransactionContext)
+}
+
+// GetAfterTransaction makes contractapi call emitAssetEvents after every successful transaction
+func (s *SimpleAssetChaincode) GetAfterTransaction() interface{} {
+	return s.emitAssetEvents
+}
+
+// emitAssetEvents emits the envelope of the asset events raised during the transaction
+func (s *SimpleAssetChaincode) emitAssetEvents(ctx *TransactionContext) error {
+	return ctx.AssetEvents().Flush(ctx.GetStub())
+}
+
+
+
+// Asset represents a single asset
+// The struct itself lives in the shared asset package, so that the low level chaincode
+// (chapter 2B) stores and reads exactly the same json document as this one.
+// This added will get stored in the ledger like this in the form of key value pairs:
+// ID : Asset{"docType": "asset", "ID" : "1", "owner": "salil", "color" : "red", ..., "version": 1}  (in json format)
+type Asset = asset.Asset
+// the `json:"color"` tags on asset.Asset tell the chaincode that whenever a function receives a parameter of type Asset,
+// unmarshal/deserialize it like shown above
+// In short, this code maps the recevied 'json string' to a 'go struct'
+// Also, when we do the vice versa meaning from 'go struct' to 'json string', the chaincode knows 
+// how to create a json string from the go struct with the help of above code
+
+
+
+// InitLedger adds a base set of assets to the ledger, only an admin (see asset.AdminRole) may do so.
+// The admin is not Alice nor Bob, so the assets are seeded with their display names
+// only, without an owner identity: no display name is registered, and only an admin may change them
+// (and hand them to their owners with TransferAssetOwnership, see asset.CheckOwner).
+func (s *SimpleAssetChaincode) InitLedger(ctx contractapi.TransactionContextInterface) error {
+	if err := asset.CheckAdmin(ctx.GetStub(), "initialize the ledger"); err != nil {
+		return err
+	}
+
+	assets := []Asset{
+		{ID: "asset1", Owner: "Alice", Color: "red", Size: 5, Price: 100},
+		{ID: "asset2", Owner: "Bob", Color: "blue", Size: 10, Price: 200},
+	}
+
+	for i := range assets {
+		// a pointer into the slice, the event raised by raiseChange keeps it
+		seeded := &assets[i]
+		err := asset.Seed(ctx.GetStub(), seeded)
+		if err == nil {
+			err = s.raiseChange(ctx, nil, seeded)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create asset %s: %v", seeded.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// CreateAsset adds a new asset to the ledger
+func (s *SimpleAssetChaincode) CreateAsset(ctx contractapi.TransactionContextInterface, newAsset Asset) error {
+	// asset.Create validates the asset, rejects an ID that exists already or an asset written as deleted
+	// (only DeleteAsset may store one), and stores it with its indexes (see QueryAssetsByOwner).
+	// The client that creates the asset owns it: its MSP ID and client ID are stored with the asset,
+	// and the display name in owner has to be its own (see asset.RegisterDisplayName)
+	err := asset.Create(ctx.GetStub(), &newAsset)
+	if err != nil {
+		return err
+	}
+	return s.raiseChange(ctx, nil, &newAsset)
+}
+
+// AssetExists checks if an asset exists in the ledger
+func (s *SimpleAssetChaincode) AssetExists(ctx contractapi.TransactionContextInterface, assetID string) (bool, error) {
+	assetBytes, err := ctx.GetStub().GetState(assetID)
+	if err != nil {
+		return false, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	return assetBytes != nil, nil
+}
+
+// QueryAsset returns the asset stored in the ledger
+func (s *SimpleAssetChaincode) QueryAsset(ctx contractapi.TransactionContextInterface, assetID string) (*Asset, error) {
+	assetBytes, err := ctx.GetStub().GetState(assetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read asset %s from world state: %v", assetID, err)
+	}
+	if assetBytes == nil {
+		return nil, fmt.Errorf("the asset %s does not exist", assetID)
+	}
+
+	return asset.Unmarshal(assetBytes)
+}
+
+// UpdateAsset updates an existing asset in the ledger
+func (s *SimpleAssetChaincode) UpdateAsset(ctx contractapi.TransactionContextInterface, asset Asset) error {
+	// QueryAsset also tells us if the asset does not exist
+	// and gives us the stored version that we have to bump
+	existing, err := s.QueryAsset(ctx, asset.ID)
+	if err != nil {
+		return err
+	}
+	// A soft deleted asset has to be restored before it can be changed
+	err = existing.CheckNotDeleted()
+	if err != nil {
+		return err
+	}
+
+	err = asset.Validate()
+	if err != nil {
+		return err
+	}
+	err = asset.CheckClientLifecycle()
+	if err != nil {
+		return err
+	}
+	// Only the owner may update the asset, and the update can't change the owner
+	err = asset.CheckUpdate(ctx.GetStub(), existing)
+	if err != nil {
+		return err
+	}
+	asset.Version = existing.Version + 1
+	asset.UpdatedBy = s.submitterID(ctx)
+
+	assetJSON, err := asset.Marshal()
+	if err != nil {
+		return err
+	}
+
+	err = ctx.GetStub().PutState(asset.ID, assetJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update asset: %v", err)
+	}
+
+	// The owner or the color may have changed, move the asset in the indexes
+	return s.recordChange(ctx, existing, &asset)
+}
+
+// recordChange follows every write of an asset: it moves the asset in the owner~id and color~id indexes
+// and raises the typed event of the write (AssetCreated, AssetUpdated, ... see package assetevents).
+// before is nil for a new asset, after is nil for a deleted one.
+// It is unexported, so contractapi does not offer it as a transaction, and it lets the functions
+// above reach asset.UpdateIndexes although their Asset parameter is named asset too.
+func (s *SimpleAssetChaincode) recordChange(ctx contractapi.TransactionContextInterface, before *Asset, after *Asset) error {
+	err := asset.UpdateIndexes(ctx.GetStub(), before, after)
+	if err != nil {
+		return err
+	}
+	return s.raiseChange(ctx, before, after)
+}
+
+// raiseChange raises the event of the change from before to after, see recordChange
+func (s *SimpleAssetChaincode) raiseChange(ctx contractapi.TransactionContextInterface, before *Asset, after *Asset) error {
+	event, err := assetevents.NewChange(ctx.GetStub(), before, after)
+	if err != nil {
+		return err
+	}
+	return s.raiseEvent(ctx, event)
+}
+
+// raiseEvent raises event on the accumulator of the transaction, emitAssetEvents emits it after the transaction.
+// A context that is not a TransactionContext (a contract function called directly from Go)
+// has no accumulator, the event is emitted on its own right away.
+func (s *SimpleAssetChaincode) raiseEvent(ctx contractapi.TransactionContextInterface, event assetevents.Event) error {
+	if txCtx, ok := ctx.(*TransactionContext); ok {
+		txCtx.AssetEvents().Raise(event)
+		return nil
+	}
+	return assetevents.Emit(ctx.GetStub(), event)
+}
+
+// submitterID returns the ID of the client that submitted the transaction ("" if unknown),
+// it is recorded in UpdatedBy so the change log of the asset can tell who made each change
+func (s *SimpleAssetChaincode) submitterID(ctx contractapi.TransactionContextInterface) string {
+	return asset.SubmitterID(ctx.GetStub())
+}
@@ -0,0 +1,997 @@
+package highlevel
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/asset"
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/assetevents"
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/chaincodetest"
+)
+
+const (
+	asset1JSON = `{"ID":"asset1","owner":"Alice","color":"red","size":5,"price":100}`
+	asset3JSON = `{"ID":"asset3","owner":"Carol","color":"green","size":7,"price":300}`
+)
+
+func newStub(t *testing.T) *chaincodetest.Stub {
+	t.Helper()
+	chaincode, err := contractapi.NewChaincode(new(SimpleAssetChaincode))
+	if err != nil {
+		t.Fatalf("failed to create chaincode: %v", err)
+	}
+	stub := chaincodetest.NewStub("asset", chaincode)
+
+	// Assets are owned by the client that creates them, so every transaction needs one
+	alice, err := chaincodetest.NewClientIdentity("Org1MSP", "Alice", nil)
+	if err != nil {
+		t.Fatalf("failed to create identity: %v", err)
+	}
+	if err := stub.SetCreator(alice); err != nil {
+		t.Fatalf("failed to set creator: %v", err)
+	}
+	return stub
+}
+
+// as makes a setup step of TestSimpleAssetChaincode run as client (Bob, Carol or admin, a client with
+// role admin) instead of Alice, e.g. as("Carol", "CreateAsset", asset3JSON)
+func as(client string, args ...string) []string {
+	return append([]string{"as:" + client}, args...)
+}
+
+// setCreator makes client the creator of the next transactions, Alice, Bob, Carol or admin
+func setCreator(t *testing.T, stub *chaincodetest.Stub, client string) {
+	t.Helper()
+	var attrs map[string]string
+	if client == "admin" {
+		attrs = map[string]string{"role": asset.AdminRole}
+	}
+	ci, err := chaincodetest.NewClientIdentity("Org1MSP", client, attrs)
+	if err != nil {
+		t.Fatalf("failed to create identity: %v", err)
+	}
+	if err := stub.SetCreator(ci); err != nil {
+		t.Fatalf("failed to set creator: %v", err)
+	}
+}
+
+func invoke(stub *chaincodetest.Stub, txID string, args ...string) (int32, string, []byte) {
+	byteArgs := make([][]byte, 0, len(args))
+	for _, arg := range args {
+		byteArgs = append(byteArgs, []byte(arg))
+	}
+	response := stub.MockInvoke(txID, byteArgs)
+	return response.Status, response.Message, response.Payload
+}
+
+func TestSimpleAssetChaincode(t *testing.T) {
+	tests := []struct {
+		name        string
+		setup       [][]string
+		args        []string
+		wantStatus  int32
+		wantMessage string
+		check       func(t *testing.T, payload []byte)
+	}{
+		{
+			name:       "init ledger",
+			setup:      [][]string{as("admin", "InitLedger")},
+			args:       []string{"QueryAsset", "asset2"},
+			wantStatus: shim.OK,
+			check: func(t *testing.T, payload []byte) {
+				a := unmarshalAsset(t, payload)
+				if a.Owner != "Bob" || a.Color != "blue" || a.Size != 10 || a.Price != 200 || a.Version != 1 {
+					t.Errorf("asset = %+v, want the seeded asset2", a)
+				}
+			},
+		},
+		{
+			name:       "create",
+			setup:      [][]string{{"CreateAsset", asset1JSON}},
+			args:       []string{"QueryAsset", "asset1"},
+			wantStatus: shim.OK,
+			check: func(t *testing.T, payload []byte) {
+				// the owner identity is the MSP ID and the client ID of Alice, the creator set by newStub
+				want := `{"docType":"asset","ID":"asset1","owner":"Alice","color":"red","size":5,"price":100,"version":1,` +
+					`"ownerMspId":"Org1MSP","ownerId":"eDUwOTo6Q049QWxpY2U6OkNOPUFsaWNl","updatedBy":"eDUwOTo6Q049QWxpY2U6OkNOPUFsaWNl"}`
+				if string(payload) != want {
+					t.Errorf("payload = %s, want %s", payload, want)
+				}
+			},
+		},
+		{
+			name:        "create duplicate",
+			setup:       [][]string{{"CreateAsset", asset1JSON}},
+			args:        []string{"CreateAsset", asset1JSON},
+			wantStatus:  shim.ERROR,
+			wantMessage: "the asset asset1 already exists",
+		},
+		{
+			name:        "create from json invalid",
+			args:        []string{"CreateAssetFromJSON", `{"ID":"asset1","owner":"Alice","color":"red","size":5,"price":0}`},
+			wantStatus:  shim.ERROR,
+			wantMessage: "asset price must be at least 1",
+		},
+		{
+			name:        "create from json with trailing data",
+			args:        []string{"CreateAssetFromJSON", asset1JSON + `{"ID":"asset2"}`},
+			wantStatus:  shim.ERROR,
+			wantMessage: "unexpected data after the json value",
+		},
+		{
+			name:        "query missing",
+			args:        []string{"QueryAsset", "asset1"},
+			wantStatus:  shim.ERROR,
+			wantMessage: "the asset asset1 does not exist",
+		},
+		{
+			name:       "query by id",
+			setup:      [][]string{{"CreateAssetFromJSON", asset1JSON}},
+			args:       []string{"QueryAssetByID", "asset1"},
+			wantStatus: shim.OK,
+			check: func(t *testing.T, payload []byte) {
+				if a := unmarshalAsset(t, payload); a.ID != "asset1" || a.Owner != "Alice" {
+					t.Errorf("asset = %+v, want asset1 of Alice", a)
+				}
+			},
+		},
+		{
+			name:       "update",
+			setup:      [][]string{{"CreateAsset", asset1JSON}, {"UpdateAsset", `{"ID":"asset1","owner":"Alice","color":"blue","size":5,"price":150}`}},
+			args:       []string{"QueryAsset", "asset1"},
+			wantStatus: shim.OK,
+			check: func(t *testing.T, payload []byte) {
+				a := unmarshalAsset(t, payload)
+				if a.Color != "blue" || a.Price != 150 || a.Version != 2 {
+					t.Errorf("asset = %+v, want color blue, price 150, version 2", a)
+				}
+			},
+		},
+		{
+			name:        "update missing",
+			args:        []string{"UpdateAsset", asset1JSON},
+			wantStatus:  shim.ERROR,
+			wantMessage: "the asset asset1 does not exist",
+		},
+		{
+			name:       "transfer",
+			setup:      [][]string{{"CreateAsset", asset1JSON}, as("Bob", "RegisterDisplayName", "Bob"), {"TransferAssetOwnership", "asset1", "Bob", "Org1MSP"}},
+			args:       []string{"QueryAsset", "asset1"},
+			wantStatus: shim.OK,
+			check: func(t *testing.T, payload []byte) {
+				a := unmarshalAsset(t, payload)
+				if a.Owner != "Bob" || a.Version != 2 {
+					t.Errorf("asset = %+v, want owner Bob, version 2", a)
+				}
+			},
+		},
+		{
+			name:        "transfer missing",
+			args:        []string{"TransferAssetOwnership", "asset1", "Bob", "Org1MSP"},
+			wantStatus:  shim.ERROR,
+			wantMessage: "asset asset1 does not exist",
+		},
+		{
+			name:        "delete",
+			setup:       [][]string{{"CreateAsset", asset1JSON}, {"DeleteAsset", "asset1"}},
+			args:        []string{"QueryAsset", "asset1"},
+			wantStatus:  shim.ERROR,
+			wantMessage: "the asset asset1 does not exist",
+		},
+		{
+			name:        "delete missing",
+			args:        []string{"DeleteAsset", "asset1"},
+			wantStatus:  shim.ERROR,
+			wantMessage: "the asset asset1 does not exist",
+		},
+		{
+			name:       "query all",
+			setup:      [][]string{as("admin", "InitLedger")},
+			args:       []string{"QueryAllAssets"},
+			wantStatus: shim.OK,
+			check: func(t *testing.T, payload []byte) {
+				var assets []*Asset
+				if err := json.Unmarshal(payload, &assets); err != nil {
+					t.Fatalf("failed to unmarshal assets: %v", err)
+				}
+				if len(assets) != 2 || assets[0].ID != "asset1" || assets[1].ID != "asset2" {
+					t.Errorf("assets = %s, want asset1 and asset2", payload)
+				}
+			},
+		},
+		{
+			name:       "query first page",
+			setup:      [][]string{as("admin", "InitLedger"), as("Carol", "CreateAsset", asset3JSON)},
+			args:       []string{"QueryAllAssetsWithPagination", "2", ""},
+			wantStatus: shim.OK,
+			check: func(t *testing.T, payload []byte) {
+				page := unmarshalPage(t, payload)
+				if page.FetchedCount != 2 || len(page.Records) != 2 || page.Records[0].ID != "asset1" || page.Bookmark != "asset3" {
+					t.Errorf("page = %s, want asset1 and asset2 with bookmark asset3", payload)
+				}
+			},
+		},
+		{
+			name:       "query last page",
+			setup:      [][]string{as("admin", "InitLedger"), as("Carol", "CreateAsset", asset3JSON)},
+			args:       []string{"QueryAllAssetsWithPagination", "2", "asset3"},
+			wantStatus: shim.OK,
+			check: func(t *testing.T, payload []byte) {
+				page := unmarshalPage(t, payload)
+				if page.FetchedCount != 1 || page.Records[0].ID != "asset3" || page.Bookmark != "" {
+					t.Errorf("page = %s, want asset3 and no bookmark", payload)
+				}
+			},
+		},
+		{
+			name:       "query empty page",
+			args:       []string{"QueryAllAssetsWithPagination", "2", ""},
+			wantStatus: shim.OK,
+			check: func(t *testing.T, payload []byte) {
+				if page := unmarshalPage(t, payload); page.FetchedCount != 0 || len(page.Records) != 0 {
+					t.Errorf("page = %s, want no records", payload)
+				}
+			},
+		},
+		{
+			name:        "query page of size zero",
+			args:        []string{"QueryAllAssetsWithPagination", "0", ""},
+			wantStatus:  shim.ERROR,
+			wantMessage: "page size must be positive",
+		},
+		{
+			name: "query by owner after transfer",
+			// the assets of InitLedger have no owner identity, so only the one an admin handed over counts
+			setup:      [][]string{as("admin", "InitLedger"), as("Bob", "RegisterDisplayName", "Bob"), as("admin", "TransferAssetOwnership", "asset1", "Bob", "Org1MSP")},
+			args:       []string{"QueryAssetsByOwner", "Bob", "Org1MSP"},
+			wantStatus: shim.OK,
+			check: func(t *testing.T, payload []byte) {
+				if ids := assetIDs(t, payload); ids != "asset1" {
+					t.Errorf("assets of Bob = %s, want asset1", ids)
+				}
+			},
+		},
+		{
+			name:       "query by previous owner",
+			setup:      [][]string{as("admin", "InitLedger"), {"RegisterDisplayName", "Alice"}, as("Bob", "RegisterDisplayName", "Bob"), as("admin", "TransferAssetOwnership", "asset1", "Bob", "Org1MSP")},
+			args:       []string{"QueryAssetsByOwner", "Alice", "Org1MSP"},
+			wantStatus: shim.OK,
+			check: func(t *testing.T, payload []byte) {
+				if ids := assetIDs(t, payload); ids != "" {
+					t.Errorf("assets of Alice = %s, want none", ids)
+				}
+			},
+		},
+		{
+			name: "query by color after update and delete",
+			setup: [][]string{
+				{"CreateAsset", asset1JSON},
+				as("Carol", "CreateAsset", asset3JSON),
+				{"UpdateAsset", `{"ID":"asset1","owner":"Alice","color":"green","size":5,"price":100}`},
+				as("Carol", "DeleteAsset", "asset3"),
+			},
+			args:       []string{"QueryAssetsByColor", "green"},
+			wantStatus: shim.OK,
+			check: func(t *testing.T, payload []byte) {
+				if ids := assetIDs(t, payload); ids != "asset1" {
+					t.Errorf("green assets = %s, want asset1", ids)
+				}
+			},
+		},
+		{
+			name:       "rich query",
+			setup:      [][]string{as("admin", "InitLedger"), as("Carol", "CreateAsset", asset3JSON)},
+			args:       []string{"QueryAssets", `{"price":{"$gte":200}}`},
+			wantStatus: shim.OK,
+			check: func(t *testing.T, payload []byte) {
+				if ids := assetIDs(t, payload); ids != "asset2,asset3" {
+					t.Errorf("assets = %s, want asset2,asset3", ids)
+				}
+			},
+		},
+		{
+			name:       "rich query sorted",
+			setup:      [][]string{as("admin", "InitLedger"), as("Carol", "CreateAsset", asset3JSON)},
+			args:       []string{"QueryAssets", `{"selector":{"size":{"$gt":0}},"sort":[{"price":"desc"}]}`},
+			wantStatus: shim.OK,
+			check: func(t *testing.T, payload []byte) {
+				if ids := assetIDs(t, payload); ids != "asset3,asset2,asset1" {
+					t.Errorf("assets = %s, want asset3,asset2,asset1", ids)
+				}
+			},
+		},
+		{
+			name:        "rich query with invalid selector",
+			args:        []string{"QueryAssets", `{"price":{"$near":200}}`},
+			wantStatus:  shim.ERROR,
+			wantMessage: "unknown operator $near",
+		},
+		{
+			name:       "rich query page",
+			setup:      [][]string{as("admin", "InitLedger"), as("Carol", "CreateAsset", asset3JSON)},
+			args:       []string{"QueryAssetsWithPagination", `{"owner":{"$in":["Alice","Carol"]}}`, "1", ""},
+			wantStatus: shim.OK,
+			check: func(t *testing.T, payload []byte) {
+				page := unmarshalPage(t, payload)
+				if page.FetchedCount != 1 || page.Records[0].ID != "asset1" || page.Bookmark == "" {
+					t.Errorf("page = %s, want asset1 and a bookmark", payload)
+				}
+			},
+		},
+		{
+			name: "history",
+			setup: [][]string{
+				{"CreateAsset", asset1JSON},
+				as("Bob", "RegisterDisplayName", "Bob"),
+				{"TransferAssetOwnership", "asset1", "Bob", "Org1MSP"},
+				as("Bob", "DeleteAsset", "asset1"),
+			},
+			args:       []string{"GetHistoryForAsset", "asset1"},
+			wantStatus: shim.OK,
+			check: func(t *testing.T, payload []byte) {
+				var history []*TransactionHistory
+				if err := json.Unmarshal(payload, &history); err != nil {
+					t.Fatalf("failed to unmarshal history: %v", err)
+				}
+				if len(history) != 3 {
+					t.Fatalf("got %d history entries, want 3", len(history))
+				}
+				wantTxIDs := []string{"setup3", "setup2", "setup0"}
+				for i, entry := range history {
+					if entry.TxId != wantTxIDs[i] {
+						t.Errorf("history[%d].TxId = %s, want %s", i, entry.TxId, wantTxIDs[i])
+					}
+				}
+				if !history[0].IsDelete || history[0].Asset != nil {
+					t.Errorf("delete entry = %+v, want isDelete and no asset", history[0])
+				}
+				if history[1].IsDelete || history[1].Asset == nil || history[1].Asset.Owner != "Bob" {
+					t.Errorf("transfer entry = %+v, want the asset of Bob", history[1])
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := newStub(t)
+			for i, args := range tt.setup {
+				if client, ok := strings.CutPrefix(args[0], "as:"); ok {
+					setCreator(t, stub, client)
+					args = args[1:]
+				}
+				status, message, _ := invoke(stub, fmt.Sprintf("setup%d", i), args...)
+				if status != shim.OK {
+					t.Fatalf("setup %v failed: %s", args, message)
+				}
+				setCreator(t, stub, "Alice")
+			}
+
+			status, message, payload := invoke(stub, "tx", tt.args...)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d (%s), want %d", status, message, tt.wantStatus)
+			}
+			if !strings.Contains(message, tt.wantMessage) {
+				t.Errorf("message = %q, want it to contain %q", message, tt.wantMessage)
+			}
+			if tt.check != nil {
+				tt.check(t, payload)
+			}
+		})
+	}
+}
+
+func unmarshalAsset(t *testing.T, payload []byte) *Asset {
+	t.Helper()
+	var a Asset
+	if err := json.Unmarshal(payload, &a); err != nil {
+		t.Fatalf("failed to unmarshal asset %s: %v", payload, err)
+	}
+	return &a
+}
+
+// unpackEventNames returns the names of the events in an AssetEvents envelope
+func unpackEventNames(t *testing.T, payload []byte) []string {
+	t.Helper()
+	events, err := assetevents.Unpack(assetevents.NameAssetEvents, payload)
+	if err != nil {
+		t.Fatalf("failed to unpack events %s: %v", payload, err)
+	}
+	names := make([]string, 0, len(events))
+	for _, event := range events {
+		names = append(names, event.EventHeader().Name)
+	}
+	return names
+}
+
+func unmarshalPage(t *testing.T, payload []byte) *asset.Page {
+	t.Helper()
+	var page asset.Page
+	if err := json.Unmarshal(payload, &page); err != nil {
+		t.Fatalf("failed to unmarshal page %s: %v", payload, err)
+	}
+	return &page
+}
+
+// assetIDs returns the IDs of the assets of payload, joined by commas
+func assetIDs(t *testing.T, payload []byte) string {
+	t.Helper()
+	var assets []*Asset
+	if err := json.Unmarshal(payload, &assets); err != nil {
+		t.Fatalf("failed to unmarshal assets %s: %v", payload, err)
+	}
+	ids := make([]string, 0, len(assets))
+	for _, a := range assets {
+		ids = append(ids, a.ID)
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestGetHistoryForAssetInRange(t *testing.T) {
+	// transaction n happens on day n of January 2024
+	stub := newStub(t)
+	// the recipients of the transfers (registered before the clock starts, so that the asset gets day 1 to 4)
+	for _, name := range []string{"Bob", "Carol"} {
+		setCreator(t, stub, name)
+		if status, message, _ := invoke(stub, "register"+name, "RegisterDisplayName", name); status != shim.OK {
+			t.Fatalf("failed to register %s: %s", name, message)
+		}
+	}
+	day := 0
+	stub.Clock = func() time.Time {
+		day++
+		return time.Date(2024, time.January, day, 12, 0, 0, 0, time.UTC)
+	}
+	// each owner hands the asset over to the next one
+	for i, step := range []struct {
+		client string
+		args   []string
+	}{
+		{"Alice", []string{"CreateAsset", asset1JSON}},
+		{"Alice", []string{"TransferAssetOwnership", "asset1", "Bob", "Org1MSP"}},
+		{"Bob", []string{"TransferAssetOwnership", "asset1", "Carol", "Org1MSP"}},
+		{"Carol", []string{"DeleteAsset", "asset1"}},
+	} {
+		setCreator(t, stub, step.client)
+		if status, message, _ := invoke(stub, fmt.Sprintf("setup%d", i), step.args...); status != shim.OK {
+			t.Fatalf("setup %v failed: %s", step.args, message)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		from        string
+		to          string
+		limit       string
+		wantTxIDs   string
+		wantMessage string
+	}{
+		{name: "everything", limit: "0", wantTxIDs: "setup3,setup2,setup1,setup0"},
+		{name: "from", from: "2024-01-02T12:00:00Z", limit: "0", wantTxIDs: "setup3,setup2,setup1"},
+		{name: "to", to: "2024-01-02T00:00:00Z", limit: "0", wantTxIDs: "setup0"},
+		{name: "from to", from: "2024-01-02T00:00:00Z", to: "2024-01-03T23:59:59Z", limit: "0", wantTxIDs: "setup2,setup1"},
+		{name: "limit", limit: "2", wantTxIDs: "setup3,setup2"},
+		{name: "from and limit", from: "2024-01-01T00:00:00+05:30", limit: "1", wantTxIDs: "setup3"},
+		{name: "invalid from", from: "01/02/2024", limit: "0", wantMessage: "expecting RFC3339"},
+		{name: "to before from", from: "2024-01-03T00:00:00Z", to: "2024-01-02T00:00:00Z", limit: "0", wantMessage: "is before from time"},
+		{name: "negative limit", limit: "-1", wantMessage: "limit must not be negative"},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, message, payload := invoke(stub, fmt.Sprintf("tx%d", i), "GetHistoryForAssetInRange", "asset1", tt.from, tt.to, tt.limit)
+			if tt.wantMessage != "" {
+				if status != shim.ERROR || !strings.Contains(message, tt.wantMessage) {
+					t.Errorf("response = %d (%s), want an error containing %q", status, message, tt.wantMessage)
+				}
+				return
+			}
+			if status != shim.OK {
+				t.Fatalf("status = %d (%s), want OK", status, message)
+			}
+
+			var history []*TransactionHistory
+			if err := json.Unmarshal(payload, &history); err != nil {
+				t.Fatalf("failed to unmarshal history: %v", err)
+			}
+			txIDs := make([]string, 0, len(history))
+			for _, entry := range history {
+				txIDs = append(txIDs, entry.TxId)
+			}
+			if got := strings.Join(txIDs, ","); got != tt.wantTxIDs {
+				t.Errorf("history = %s, want %s", got, tt.wantTxIDs)
+			}
+			if len(history) > 0 && history[len(history)-1].TxId == "setup0" && history[len(history)-1].Timestamp != "2024-01-01T12:00:00Z" {
+				t.Errorf("timestamp of setup0 = %s, want 2024-01-01T12:00:00Z", history[len(history)-1].Timestamp)
+			}
+		})
+	}
+}
+
+func TestGetAssetChangeLog(t *testing.T) {
+	alice, err := chaincodetest.NewClientIdentity("Org1MSP", "alice", nil)
+	if err != nil {
+		t.Fatalf("failed to create identity: %v", err)
+	}
+	bob, err := chaincodetest.NewClientIdentity("Org2MSP", "bob", nil)
+	if err != nil {
+		t.Fatalf("failed to create identity: %v", err)
+	}
+
+	stub := newStub(t)
+	for i, step := range []struct {
+		client *chaincodetest.ClientIdentity
+		args   []string
+	}{
+		{bob, []string{"RegisterDisplayName", "Bob"}},
+		{alice, []string{"CreateAsset", asset1JSON}},
+		{alice, []string{"TransferAssetOwnership", "asset1", "Bob", "Org2MSP"}},
+		{bob, []string{"UpdateAsset", `{"ID":"asset1","owner":"Bob","color":"red","size":5,"price":200}`}},
+		{bob, []string{"DeleteAsset", "asset1"}},
+	} {
+		if err := stub.SetCreator(step.client); err != nil {
+			t.Fatalf("failed to set creator: %v", err)
+		}
+		if status, message, _ := invoke(stub, fmt.Sprintf("setup%d", i), step.args...); status != shim.OK {
+			t.Fatalf("setup %v failed: %s", step.args, message)
+		}
+	}
+
+	status, message, payload := invoke(stub, "tx", "GetAssetChangeLog", "asset1")
+	if status != shim.OK {
+		t.Fatalf("status = %d (%s), want OK", status, message)
+	}
+	var changeLog []*asset.ChangeLogEntry
+	if err := json.Unmarshal(payload, &changeLog); err != nil {
+		t.Fatalf("failed to unmarshal change log: %v", err)
+	}
+
+	want := []struct {
+		txID     string
+		action   string
+		clientID string
+		changes  string
+	}{
+		{"setup1", asset.ActionCreated, alice.ID, "ID: →asset1, color: →red, owner: →Alice, ownerId: →" + alice.ID + ", ownerMspId: →Org1MSP, price: →100, size: →5"},
+		{"setup2", asset.ActionUpdated, alice.ID, "owner: Alice→Bob, ownerId: " + alice.ID + "→" + bob.ID + ", ownerMspId: Org1MSP→Org2MSP"},
+		{"setup3", asset.ActionUpdated, bob.ID, "price: 100→200"},
+		{"setup4", asset.ActionDeleted, "", ""},
+	}
+	if len(changeLog) != len(want) {
+		t.Fatalf("change log = %s, want %d entries", payload, len(want))
+	}
+	for i, w := range want {
+		entry := changeLog[i]
+		changes := make([]string, 0, len(entry.Changes))
+		for _, change := range entry.Changes {
+			changes = append(changes, change.String())
+		}
+		if entry.TxId != w.txID || entry.Action != w.action || entry.ClientID != w.clientID || strings.Join(changes, ", ") != w.changes {
+			t.Errorf("entry %d = %s %s %s [%s], want %s %s %s [%s]", i,
+				entry.TxId, entry.Action, entry.ClientID, strings.Join(changes, ", "),
+				w.txID, w.action, w.clientID, w.changes)
+		}
+	}
+}
+
+func TestSoftDelete(t *testing.T) {
+	alice, err := chaincodetest.NewClientIdentity("Org1MSP", "alice", nil)
+	if err != nil {
+		t.Fatalf("failed to create identity: %v", err)
+	}
+	bob, err := chaincodetest.NewClientIdentity("Org1MSP", "bob", nil)
+	if err != nil {
+		t.Fatalf("failed to create identity: %v", err)
+	}
+	carol, err := chaincodetest.NewClientIdentity("Org1MSP", "carol", nil)
+	if err != nil {
+		t.Fatalf("failed to create identity: %v", err)
+	}
+	admin, err := chaincodetest.NewClientIdentity("Org1MSP", "admin", map[string]string{"role": asset.AdminRole})
+	if err != nil {
+		t.Fatalf("failed to create identity: %v", err)
+	}
+
+	chaincode, err := contractapi.NewChaincode(new(SimpleAssetChaincode))
+	if err != nil {
+		t.Fatalf("failed to create chaincode: %v", err)
+	}
+	stub := chaincodetest.NewStub("asset", chaincode)
+
+	step := func(client *chaincodetest.ClientIdentity, txID string, args ...string) (int32, string, []byte) {
+		t.Helper()
+		if err := stub.SetCreator(client); err != nil {
+			t.Fatalf("failed to set creator: %v", err)
+		}
+		return invoke(stub, txID, args...)
+	}
+	mustSucceed := func(client *chaincodetest.ClientIdentity, txID string, args ...string) []byte {
+		t.Helper()
+		status, message, payload := step(client, txID, args...)
+		if status != shim.OK {
+			t.Fatalf("%v failed: %s", args, message)
+		}
+		return payload
+	}
+	mustFail := func(client *chaincodetest.ClientIdentity, txID string, want string, args ...string) {
+		t.Helper()
+		status, message, _ := step(client, txID, args...)
+		if status == shim.OK || !strings.Contains(message, want) {
+			t.Errorf("%v = %d (%s), want an error containing %q", args, status, message, want)
+		}
+	}
+
+	// The delete mode is in the ledger, hard until an admin sets it
+	if mode := string(mustSucceed(alice, "mode", "GetDeleteMode")); mode != asset.DeleteModeHard {
+		t.Errorf("GetDeleteMode = %s, want %s", mode, asset.DeleteModeHard)
+	}
+	mustFail(alice, "soft by alice", "only a client with role admin", "SetDeleteMode", "soft")
+	mustFail(admin, "unknown mode", "delete mode must be", "SetDeleteMode", "archive")
+	mustSucceed(admin, "soft", "SetDeleteMode", "soft")
+
+	mustSucceed(alice, "create1", "CreateAsset", asset1JSON)
+	mustSucceed(carol, "create3", "CreateAsset", asset3JSON)
+	mustSucceed(alice, "delete", "DeleteAsset", "asset1")
+
+	// The asset is still in the world state, marked as deleted
+	deleted := unmarshalAsset(t, mustSucceed(alice, "q1", "QueryAsset", "asset1"))
+	if deleted.Status != asset.StatusDeleted || deleted.DeletedBy != alice.ID || deleted.DeletedAt == "" {
+		t.Errorf("deleted asset = %+v, want status deleted, deletedBy %s and deletedAt", deleted, alice.ID)
+	}
+	if got := assetIDs(t, mustSucceed(alice, "q2", "QueryAllAssets")); got != "asset3" {
+		t.Errorf("QueryAllAssets = %s, want asset3", got)
+	}
+	if got := assetIDs(t, mustSucceed(alice, "q3", "QueryAssetsByOwner", "Alice", "Org1MSP")); got != "" {
+		t.Errorf("QueryAssetsByOwner = %s, want nothing", got)
+	}
+	if got := assetIDs(t, mustSucceed(alice, "q4", "QueryDeletedAssets")); got != "asset1" {
+		t.Errorf("QueryDeletedAssets = %s, want asset1", got)
+	}
+	// rich queries hide the deleted asset too, even when the selector asks for it
+	if got := assetIDs(t, mustSucceed(alice, "q4a", "QueryAssets", `{"color":{"$in":["red","green"]}}`)); got != "asset3" {
+		t.Errorf("QueryAssets = %s, want asset3", got)
+	}
+	if got := assetIDs(t, mustSucceed(alice, "q4b", "QueryAssets", `{"selector":{"status":"deleted"}}`)); got != "" {
+		t.Errorf("QueryAssets of deleted assets = %s, want nothing", got)
+	}
+	var page asset.Page
+	if err := json.Unmarshal(mustSucceed(alice, "q4c", "QueryAssetsWithPagination", `{"color":{"$in":["red","green"]}}`, "1", ""), &page); err != nil {
+		t.Fatalf("failed to unmarshal page: %v", err)
+	}
+	if len(page.Records) != 1 || page.Records[0].ID != "asset3" {
+		t.Errorf("QueryAssetsWithPagination = %+v, want a full page with asset3", page.Records)
+	}
+
+	mustFail(alice, "update", "is deleted", "UpdateAsset", asset1JSON)
+	mustFail(alice, "transfer", "is deleted", "TransferAssetOwnership", "asset1", "Bob", "Org1MSP")
+	mustFail(alice, "delete again", "is deleted", "DeleteAsset", "asset1")
+	mustFail(alice, "create deleted", "cannot be written as deleted", "CreateAsset",
+		`{"ID":"asset9","owner":"Alice","color":"red","size":5,"price":100,"status":"deleted"}`)
+	mustFail(bob, "restore by bob", "may not restore", "RestoreAsset", "asset1")
+	mustFail(admin, "restore active", "is not deleted", "RestoreAsset", "asset3")
+
+	mustSucceed(admin, "restore", "RestoreAsset", "asset1")
+	restored := unmarshalAsset(t, mustSucceed(alice, "q5", "QueryAsset", "asset1"))
+	if restored.Status != asset.StatusActive || restored.DeletedBy != "" || restored.DeletedAt != "" || restored.Version != 3 {
+		t.Errorf("restored asset = %+v, want active at version 3", restored)
+	}
+	if got := assetIDs(t, mustSucceed(alice, "q6", "QueryAssetsByOwner", "Alice", "Org1MSP")); got != "asset1" {
+		t.Errorf("QueryAssetsByOwner = %s, want asset1", got)
+	}
+
+	var changeLog []*asset.ChangeLogEntry
+	if err := json.Unmarshal(mustSucceed(alice, "q7", "GetAssetChangeLog", "asset1"), &changeLog); err != nil {
+		t.Fatalf("failed to unmarshal change log: %v", err)
+	}
+	actions := make([]string, 0, len(changeLog))
+	for _, entry := range changeLog {
+		actions = append(actions, entry.Action)
+	}
+	if got := strings.Join(actions, ","); got != "created,deleted,restored" {
+		t.Errorf("change log actions = %s, want created,deleted,restored", got)
+	}
+}
+
+func TestTwoPhaseTransfer(t *testing.T) {
+	clients := map[string]*chaincodetest.ClientIdentity{}
+	for _, name := range []string{"Alice", "Bob", "Carol"} {
+		ci, err := chaincodetest.NewClientIdentity("Org1MSP", name, nil)
+		if err != nil {
+			t.Fatalf("failed to create identity: %v", err)
+		}
+		clients[name] = ci
+	}
+
+	stub := newStub(t)
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+	stub.Clock = func() time.Time { return now }
+
+	// run invokes the chaincode as client and returns the event emitted by a successful transaction,
+	// or the names of the asset events in its AssetEvents envelope
+	run := func(client string, txID string, args ...string) (int32, string, string) {
+		t.Helper()
+		if err := stub.SetCreator(clients[client]); err != nil {
+			t.Fatalf("failed to set creator: %v", err)
+		}
+		status, message, _ := invoke(stub, txID, args...)
+		event := ""
+		select {
+		case e := <-stub.ChaincodeEventsChannel:
+			event = e.EventName
+			if event == assetevents.NameAssetEvents {
+				event = strings.Join(unpackEventNames(t, e.Payload), ",")
+			}
+		default:
+		}
+		return status, message, event
+	}
+
+	tests := []struct {
+		name        string
+		client      string
+		args        []string
+		advance     time.Duration
+		wantMessage string
+		wantEvent   string
+	}{
+		{name: "create", client: "Alice", args: []string{"CreateAsset", asset1JSON}, wantEvent: assetevents.NameAssetCreated},
+		{name: "register Bob", client: "Bob", args: []string{"RegisterDisplayName", "Bob"}},
+		{name: "register Carol", client: "Carol", args: []string{"RegisterDisplayName", "Carol"}},
+		{name: "propose by non owner", client: "Bob", args: []string{"ProposeTransfer", "asset1", "Bob", "Org1MSP", "1h"}, wantMessage: "is not the owner of the asset asset1 (Alice)"},
+		{name: "propose to unknown name", client: "Alice", args: []string{"ProposeTransfer", "asset1", "Dave", "Org1MSP", "1h"}, wantMessage: "no client of Org1MSP registered the display name Dave"},
+		{name: "propose bad validity", client: "Alice", args: []string{"ProposeTransfer", "asset1", "Bob", "Org1MSP", "soon"}, wantMessage: "expecting a duration"},
+		{name: "propose", client: "Alice", args: []string{"ProposeTransfer", "asset1", "Bob", "Org1MSP", "1h"}, wantEvent: assetevents.NameTransferProposed},
+		{name: "propose while pending", client: "Alice", args: []string{"ProposeTransfer", "asset1", "Carol", "Org1MSP", "1h"}, wantMessage: "already has a pending transfer to Bob"},
+		{name: "accept by other client", client: "Carol", args: []string{"AcceptTransfer", "asset1"}, wantMessage: "is not the recipient of the transfer"},
+		{name: "accept after expiry", client: "Bob", args: []string{"AcceptTransfer", "asset1"}, advance: 2 * time.Hour, wantMessage: "expired at 2024-01-01T13:00:00Z"},
+		{name: "propose again after expiry", client: "Alice", args: []string{"ProposeTransfer", "asset1", "Carol", "Org1MSP", "1h"}, wantEvent: assetevents.NameTransferProposed},
+		{name: "reject", client: "Carol", args: []string{"RejectTransfer", "asset1"}, wantEvent: assetevents.NameTransferRejected},
+		{name: "nothing pending", client: "Carol", args: []string{"AcceptTransfer", "asset1"}, wantMessage: "has no pending transfer"},
+		{name: "propose to Bob", client: "Alice", args: []string{"ProposeTransfer", "asset1", "Bob", "Org1MSP", "1h"}, wantEvent: assetevents.NameTransferProposed},
+		{name: "accept", client: "Bob", args: []string{"AcceptTransfer", "asset1"}, wantEvent: assetevents.NameAssetTransferred + "," + assetevents.NameTransferAccepted},
+	}
+	for i, tt := range tests {
+		now = now.Add(tt.advance)
+		status, message, event := run(tt.client, fmt.Sprintf("tx%d", i), tt.args...)
+		if tt.wantMessage != "" {
+			if status == shim.OK || !strings.Contains(message, tt.wantMessage) {
+				t.Errorf("%s: got %d (%s), want an error containing %q", tt.name, status, message, tt.wantMessage)
+			}
+			continue
+		}
+		if status != shim.OK || event != tt.wantEvent {
+			t.Errorf("%s: got %d (%s) with event %q, want OK with event %q", tt.name, status, message, event, tt.wantEvent)
+		}
+	}
+
+	_, _, payload := invoke(stub, "query", "QueryAsset", "asset1")
+	if transferred := unmarshalAsset(t, payload); transferred.Owner != "Bob" || transferred.UpdatedBy != clients["Bob"].ID {
+		t.Errorf("asset = %+v, want owned and last updated by Bob", transferred)
+	}
+	if status, _, _ := invoke(stub, "pending", "QueryPendingTransfer", "asset1"); status == shim.OK {
+		t.Error("QueryPendingTransfer succeeded, want no pending transfer after the accept")
+	}
+}
+
+func TestEventsOfBatch(t *testing.T) {
+	stub := newStub(t)
+	setCreator(t, stub, "admin")
+	if status, message, _ := invoke(stub, "init", "InitLedger"); status != shim.OK {
+		t.Fatalf("InitLedger failed: %s", message)
+	}
+
+	// both assets of InitLedger are announced by the single event of the transaction
+	e := <-stub.ChaincodeEventsChannel
+	if e.EventName != assetevents.NameAssetEvents {
+		t.Fatalf("event name = %s, want %s", e.EventName, assetevents.NameAssetEvents)
+	}
+	events, err := assetevents.Unpack(e.EventName, e.Payload)
+	if err != nil {
+		t.Fatalf("Unpack failed: %v", err)
+	}
+	var created []string
+	for _, event := range events {
+		created = append(created, event.(*assetevents.AssetCreated).After.ID)
+		if event.EventHeader().TxID != "init" {
+			t.Errorf("event %+v, want it raised by the transaction init", event.EventHeader())
+		}
+	}
+	if strings.Join(created, ",") != "asset1,asset2" {
+		t.Errorf("created assets = %v, want asset1 and asset2 in order", created)
+	}
+
+	// a transaction that fails raises nothing
+	if status, _, _ := invoke(stub, "init again", "InitLedger"); status == shim.OK {
+		t.Fatal("InitLedger succeeded twice, want the assets to exist already")
+	}
+	select {
+	case e := <-stub.ChaincodeEventsChannel:
+		t.Errorf("failed transaction emitted %s", e.EventName)
+	default:
+	}
+	// neither does a query
+	invoke(stub, "query", "QueryAsset", "asset1")
+	select {
+	case e := <-stub.ChaincodeEventsChannel:
+		t.Errorf("query emitted %s", e.EventName)
+	default:
+	}
+}
+
+func TestOwnerOnly(t *testing.T) {
+	bob, err := chaincodetest.NewClientIdentity("Org2MSP", "Bob", nil)
+	if err != nil {
+		t.Fatalf("failed to create identity: %v", err)
+	}
+
+	// newStub runs every transaction as Alice, who creates asset1
+	stub := newStub(t)
+	if status, message, _ := invoke(stub, "create", "CreateAsset", asset1JSON); status != shim.OK {
+		t.Fatalf("CreateAsset failed: %s", message)
+	}
+	if status, message, _ := invoke(stub, "update owner", "UpdateAsset", `{"ID":"asset1","owner":"Bob","color":"red","size":5,"price":100}`); status == shim.OK ||
+		!strings.Contains(message, "can't change the owner of the asset asset1 from Alice to Bob") {
+		t.Errorf("UpdateAsset changing the owner = %d (%s), want an error", status, message)
+	}
+
+	if err := stub.SetCreator(bob); err != nil {
+		t.Fatalf("failed to set creator: %v", err)
+	}
+	for _, tt := range []struct {
+		args        []string
+		wantMessage string
+	}{
+		{[]string{"UpdateAsset", `{"ID":"asset1","owner":"Alice","color":"red","size":5,"price":1}`}, "is not the owner of the asset asset1 (Alice)"},
+		{[]string{"TransferAssetOwnership", "asset1", "Alice", "Org1MSP"}, "is not the owner of the asset asset1 (Alice)"},
+		{[]string{"DeleteAsset", "asset1"}, "is not the owner of the asset asset1 (Alice)"},
+		{[]string{"CreateAsset", `{"ID":"asset2","owner":"Alice","color":"red","size":5,"price":100}`}, "can't register the display name Alice"},
+		{[]string{"RegisterDisplayName", "Alice"}, "can't register the display name Alice"},
+		// a client only claims the common name of its certificate
+		{[]string{"RegisterDisplayName", "Carol"}, "can't register the display name Carol, only the common name of its certificate"},
+		{[]string{"CreateAsset", `{"ID":"asset2","owner":"Carol","color":"red","size":5,"price":100}`}, "can't register the display name Carol"},
+		{[]string{"AssignDisplayName", "Robert", "Org2MSP", bob.ID}, "only a client with role admin can assign a display name"},
+	} {
+		status, message, _ := invoke(stub, "bob", tt.args...)
+		if status == shim.OK || !strings.Contains(message, tt.wantMessage) {
+			t.Errorf("%v by Bob = %d (%s), want an error containing %q", tt.args, status, message, tt.wantMessage)
+		}
+	}
+
+	_, _, payload := invoke(stub, "query", "QueryDisplayName", "Alice", "Org1MSP")
+	var displayName asset.DisplayName
+	if err := json.Unmarshal(payload, &displayName); err != nil || displayName.MSPID != "Org1MSP" || displayName.ClientID == bob.ID {
+		t.Errorf("QueryDisplayName(Alice) = %s, want the identity of Alice", payload)
+	}
+
+	// an admin only maps the names of its own MSP
+	setCreator(t, stub, "admin")
+	if status, message, _ := invoke(stub, "assign other", "AssignDisplayName", "Robert", "Org2MSP", bob.ID); status == shim.OK ||
+		!strings.Contains(message, "can't assign the display names of Org2MSP") {
+		t.Errorf("AssignDisplayName by an admin of Org1MSP = %d (%s), want an error", status, message)
+	}
+
+	// an admin of Org2MSP maps any other name, then Bob owns what he creates under it
+	admin, err := chaincodetest.NewClientIdentity("Org2MSP", "admin", map[string]string{"role": asset.AdminRole})
+	if err != nil {
+		t.Fatalf("failed to create identity: %v", err)
+	}
+	if err := stub.SetCreator(admin); err != nil {
+		t.Fatalf("failed to set creator: %v", err)
+	}
+	if status, message, _ := invoke(stub, "assign", "AssignDisplayName", "Robert", "Org2MSP", bob.ID); status != shim.OK {
+		t.Fatalf("AssignDisplayName failed: %s", message)
+	}
+	if err := stub.SetCreator(bob); err != nil {
+		t.Fatalf("failed to set creator: %v", err)
+	}
+	if status, message, _ := invoke(stub, "create robert", "CreateAsset", `{"ID":"asset2","owner":"Robert","color":"red","size":5,"price":100}`); status != shim.OK {
+		t.Fatalf("CreateAsset as Robert failed: %s", message)
+	}
+	_, _, payload = invoke(stub, "query robert", "QueryAsset", "asset2")
+	if created := unmarshalAsset(t, payload); created.Owner != "Robert" || created.OwnerID != bob.ID {
+		t.Errorf("asset = %+v, want owned by Bob as Robert", created)
+	}
+}
+
+func TestDisplayNamesPerMSP(t *testing.T) {
+	otherAlice, err := chaincodetest.NewClientIdentity("Org2MSP", "Alice", nil)
+	if err != nil {
+		t.Fatalf("failed to create identity: %v", err)
+	}
+
+	// newStub runs every transaction as Alice of Org1MSP, who creates asset1
+	stub := newStub(t)
+	if status, message, _ := invoke(stub, "create", "CreateAsset", asset1JSON); status != shim.OK {
+		t.Fatalf("CreateAsset failed: %s", message)
+	}
+
+	// a client of Org2MSP with the same common name claims the name in its own MSP only
+	if err := stub.SetCreator(otherAlice); err != nil {
+		t.Fatalf("failed to set creator: %v", err)
+	}
+	if status, message, _ := invoke(stub, "register", "RegisterDisplayName", "Alice"); status != shim.OK {
+		t.Fatalf("RegisterDisplayName in Org2MSP failed: %s", message)
+	}
+	for _, tt := range []struct {
+		mspID    string
+		clientID string
+	}{
+		{"Org1MSP", "eDUwOTo6Q049QWxpY2U6OkNOPUFsaWNl"},
+		{"Org2MSP", otherAlice.ID},
+	} {
+		_, _, payload := invoke(stub, "query "+tt.mspID, "QueryDisplayName", "Alice", tt.mspID)
+		var displayName asset.DisplayName
+		if err := json.Unmarshal(payload, &displayName); err != nil || displayName.MSPID != tt.mspID || displayName.ClientID != tt.clientID {
+			t.Errorf("QueryDisplayName(Alice, %s) = %s, want the identity of Alice of %s", tt.mspID, payload, tt.mspID)
+		}
+	}
+	if status, message, _ := invoke(stub, "create other", "CreateAsset", `{"ID":"asset2","owner":"Alice","color":"blue","size":5,"price":100}`); status != shim.OK {
+		t.Fatalf("CreateAsset in Org2MSP failed: %s", message)
+	}
+
+	// the owner index tells both apart
+	for _, tt := range []struct {
+		mspID string
+		want  string
+	}{
+		{"Org1MSP", "asset1"},
+		{"Org2MSP", "asset2"},
+	} {
+		_, _, payload := invoke(stub, "owned "+tt.mspID, "QueryAssetsByOwner", "Alice", tt.mspID)
+		if got := assetIDs(t, payload); got != tt.want {
+			t.Errorf("QueryAssetsByOwner(Alice, %s) = %s, want %s", tt.mspID, got, tt.want)
+		}
+	}
+
+	// a transfer names the MSP of the new owner
+	if status, message, _ := invoke(stub, "transfer", "TransferAssetOwnership", "asset2", "Alice", "Org1MSP"); status != shim.OK {
+		t.Fatalf("TransferAssetOwnership to Org1MSP failed: %s", message)
+	}
+	_, _, payload := invoke(stub, "query", "QueryAsset", "asset2")
+	if moved := unmarshalAsset(t, payload); moved.OwnerMSPID != "Org1MSP" || moved.OwnerID != "eDUwOTo6Q049QWxpY2U6OkNOPUFsaWNl" {
+		t.Errorf("asset = %+v, want owned by Alice of Org1MSP", moved)
+	}
+}
+
+func TestInitLedgerLeavesOwnersUnbound(t *testing.T) {
+	// only an admin seeds the ledger, and neither seeded name is bound to it
+	stub := newStub(t)
+	if status, message, _ := invoke(stub, "init by Alice", "InitLedger"); status == shim.OK ||
+		!strings.Contains(message, "only a client with role admin can initialize the ledger") {
+		t.Errorf("InitLedger by Alice = %d (%s), want an error", status, message)
+	}
+	setCreator(t, stub, "admin")
+	if status, message, _ := invoke(stub, "init", "InitLedger"); status != shim.OK {
+		t.Fatalf("InitLedger failed: %s", message)
+	}
+	setCreator(t, stub, "Alice")
+	for _, name := range []string{"Alice", "Bob"} {
+		if status, _, payload := invoke(stub, "query "+name, "QueryDisplayName", name, "Org1MSP"); status == shim.OK {
+			t.Errorf("QueryDisplayName(%s) = %s, want no client registered", name, payload)
+		}
+	}
+	if status, message, _ := invoke(stub, "transfer", "TransferAssetOwnership", "asset2", "Alice", "Org1MSP"); status == shim.OK ||
+		!strings.Contains(message, "has no owner identity") {
+		t.Errorf("TransferAssetOwnership of a seeded asset by Alice = %d (%s), want an error", status, message)
+	}
+
+	// Bob registers his name, and an admin hands him the seeded asset
+	setCreator(t, stub, "Bob")
+	if status, message, _ := invoke(stub, "register", "RegisterDisplayName", "Bob"); status != shim.OK {
+		t.Fatalf("RegisterDisplayName failed: %s", message)
+	}
+	setCreator(t, stub, "admin")
+	if status, message, _ := invoke(stub, "hand over", "TransferAssetOwnership", "asset2", "Bob", "Org1MSP"); status != shim.OK {
+		t.Fatalf("TransferAssetOwnership by admin failed: %s", message)
+	}
+	_, _, payload := invoke(stub, "query", "QueryAsset", "asset2")
+	if handed := unmarshalAsset(t, payload); handed.Owner != "Bob" || handed.OwnerID == "" {
+		t.Errorf("asset = %+v, want owned by the identity of Bob", handed)
+	}
+}
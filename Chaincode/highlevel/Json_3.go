@@ -0,0 +1,33 @@
+package highlevel
+
+import (
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/asset"
+)
+
+// In this lesson the client sends the asset as a plain json string instead of an Asset,
+// so we unmarshal and validate it ourselves instead of letting contractapi do it.
+// The Asset is the shared asset.Asset, so the Status set here is kept by the other chaincodes too.
+
+// CreateAssetFromJSON creates a new asset in the ledger from its json representation.
+func (s *SimpleAssetChaincode) CreateAssetFromJSON(ctx contractapi.TransactionContextInterface, assetJSON string) error {
+	// Unmarshal the asset JSON into an Asset struct
+	// UnmarshalStrict rejects unknown fields, duplicate keys, trailing data and oversized
+	// payloads, which json.Unmarshal would silently accept
+	newAsset, err := asset.UnmarshalStrict([]byte(assetJSON))
+	if err != nil {
+		return err
+	}
+
+	// asset.Create validates the asset fields against the validate tags of asset.Asset
+	// (ID and owner are required, price must be positive, color must be a known one...)
+	// and reports every violation at once in a structured asset.ValidationError.
+	// It also rejects an ID that exists already, binds the owner to the client and stores the asset
+	err = asset.Create(ctx.GetStub(), newAsset)
+	if err != nil {
+		return err
+	}
+
+	return s.raiseChange(ctx, nil, newAsset)
+}
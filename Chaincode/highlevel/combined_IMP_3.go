@@ -0,0 +1,409 @@
+// A smart contract can access a range of functionality in a smart contract
+// via the transaction context 'stub' and 'clientIdentity'.
+
+// In this lesson, we have performed CRUD operations using 'STUB'
+
+
+// To get all the functions of GetStub().{function}
+// refer 
+// https://hyperledger-fabric.readthedocs.io/en/release-2.2/developapps/transactioncontext.html#stub
+// OR
+// https://pkg.go.dev/github.com/hyperledger/fabric-chaincode-go/shim#ChaincodeStubInterface
+
+package highlevel
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/asset"
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/assetevents"
+)
+
+// 1. CreateAsset and 2. UpdateAsset: Use the PutState method of the stub to store the new/updated asset in the ledger.
+// Both of them (along with AssetExists and QueryAsset) are written in FirstHighLevel_2A.go,
+// this lesson continues with the rest of the CRUD operations on the same SimpleAssetChaincode.
+// Note that PutState() accepts the value in json format and not in go data types,
+// so the asset is serialized with asset.Marshal() before it is stored.
+// asset.Marshal() writes canonical json (sorted keys, normalized numbers) and not plain json.Marshal output:
+// every endorsing peer has to write exactly the same bytes, or the endorsements don't match.
+
+
+// 3. TransferAssetOwnership: Use the PutState method of the stub to update the ownership field of the existing asset in the ledger.
+// Only the owner may transfer the asset (checked with the client identity, see asset.CheckOwner), and newOwner
+// is the display name of the recipient among those of newOwnerMSPID, which tells the client identity that owns the asset from now on.
+func (s *SimpleAssetChaincode) TransferAssetOwnership(ctx contractapi.TransactionContextInterface, assetID string, newOwner string, newOwnerMSPID string) error {
+
+	// Retrieve existing asset from the ledger
+	assetJSON, err := ctx.GetStub().GetState(assetID)
+	if err != nil {
+		return fmt.Errorf("failed to read asset from ledger: %v", err)
+	}
+	if assetJSON == nil {
+		return fmt.Errorf("asset %s does not exist", assetID)
+	}
+
+	// Deserialize existing asset JSON
+	existing, err := asset.Unmarshal(assetJSON)
+	if err != nil {
+		return err
+	}
+
+	// A soft deleted asset can't change hands
+	err = existing.CheckNotDeleted()
+	if err != nil {
+		return err
+	}
+	err = existing.CheckOwner(ctx.GetStub())
+	if err != nil {
+		return err
+	}
+	recipient, err := asset.ResolveDisplayName(ctx.GetStub(), newOwner, newOwnerMSPID)
+	if err != nil {
+		return err
+	}
+
+	// Keep a copy of the asset before the transfer, to move it in the owner~id index
+	before := *existing
+
+	// Update ownership fields
+	existing.SetOwner(recipient)
+	existing.Version++
+	existing.UpdatedBy = asset.SubmitterID(ctx.GetStub())
+	err = existing.Validate()
+	if err != nil {
+		return err
+	}
+
+	// Serialize updated asset to JSON
+	updatedAssetJSON, err := existing.Marshal()
+	if err != nil {
+		return err
+	}
+
+	// Update asset in the ledger
+	err = ctx.GetStub().PutState(assetID, updatedAssetJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update asset in ledger: %v", err)
+	}
+
+	// An offer of the previous owner (see ProposeTransfer) does not stand anymore
+	err = asset.DropPendingTransfer(ctx.GetStub(), assetID)
+	if err != nil {
+		return err
+	}
+
+	return s.recordChange(ctx, &before, existing)
+}
+
+
+// 3a. RegisterDisplayName: the owner of an asset is a client identity (MSP ID + client ID, see asset.Submitter),
+// which is a long x509::CN=...::... string. The asset also keeps a readable display name in owner,
+// and a display name maps to one client identity of an MSP only: the first client of that MSP that registers it (or creates an asset with it).
+// TransferAssetOwnership and ProposeTransfer take the display name and MSP ID of the recipient, so the recipient has to register it first.
+// A client registers the common name of its certificate only, so that nobody can claim the name of somebody else.
+func (s *SimpleAssetChaincode) RegisterDisplayName(ctx contractapi.TransactionContextInterface, name string) (*asset.DisplayName, error) {
+	return asset.RegisterDisplayName(ctx.GetStub(), name)
+}
+
+// AssignDisplayName maps any other display name to a client identity (its MSP ID and client ID), only an admin of that MSP may do so
+func (s *SimpleAssetChaincode) AssignDisplayName(ctx contractapi.TransactionContextInterface, name string, mspID string, clientID string) (*asset.DisplayName, error) {
+	return asset.AssignDisplayName(ctx.GetStub(), name, mspID, clientID)
+}
+
+// QueryDisplayName returns the client identity a display name of mspID maps to
+func (s *SimpleAssetChaincode) QueryDisplayName(ctx contractapi.TransactionContextInterface, name string, mspID string) (*asset.DisplayName, error) {
+	return asset.ResolveDisplayName(ctx.GetStub(), name, mspID)
+}
+
+
+// 3b. ProposeTransfer, AcceptTransfer and RejectTransfer: TransferAssetOwnership above hands the asset over at once,
+// the recipient has no say. With the two-phase transfer
+// the owner offers the asset to a recipient (the offer is stored as a pending transfer, valid for validFor, e.g. "24h"),
+// and the asset only changes hands when the recipient accepts it. The recipient can also reject it.
+// Each step raises its event (TransferProposed, TransferAccepted or TransferRejected, see package assetevents)
+// with the pending transfer, and the accepted transfer also raises AssetTransferred like every other change of owner.
+func (s *SimpleAssetChaincode) ProposeTransfer(ctx contractapi.TransactionContextInterface, assetID string, newOwner string, newOwnerMSPID string, validFor string) (*asset.PendingTransfer, error) {
+	transfer, err := asset.ProposeTransfer(ctx.GetStub(), assetID, newOwner, newOwnerMSPID, validFor)
+	if err != nil {
+		return nil, err
+	}
+	return transfer, s.raiseTransferEvent(ctx, assetevents.NameTransferProposed, transfer)
+}
+
+func (s *SimpleAssetChaincode) AcceptTransfer(ctx contractapi.TransactionContextInterface, assetID string) (*Asset, error) {
+	transfer, before, after, err := asset.AcceptTransfer(ctx.GetStub(), assetID)
+	if err != nil {
+		return nil, err
+	}
+	event, err := assetevents.NewChange(ctx.GetStub(), before, after)
+	if err != nil {
+		return nil, err
+	}
+	err = s.raiseEvent(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+	return after, s.raiseTransferEvent(ctx, assetevents.NameTransferAccepted, transfer)
+}
+
+func (s *SimpleAssetChaincode) RejectTransfer(ctx contractapi.TransactionContextInterface, assetID string) error {
+	transfer, err := asset.RejectTransfer(ctx.GetStub(), assetID)
+	if err != nil {
+		return err
+	}
+	return s.raiseTransferEvent(ctx, assetevents.NameTransferRejected, transfer)
+}
+
+// raiseTransferEvent raises the event name of a step of the two-phase transfer
+func (s *SimpleAssetChaincode) raiseTransferEvent(ctx contractapi.TransactionContextInterface, name string, transfer *asset.PendingTransfer) error {
+	event, err := assetevents.NewTransfer(ctx.GetStub(), name, transfer)
+	if err != nil {
+		return err
+	}
+	return s.raiseEvent(ctx, event)
+}
+
+// QueryPendingTransfer returns the offer waiting for an answer of its recipient
+func (s *SimpleAssetChaincode) QueryPendingTransfer(ctx contractapi.TransactionContextInterface, assetID string) (*asset.PendingTransfer, error) {
+	return asset.QueryPendingTransfer(ctx.GetStub(), assetID)
+}
+
+
+// 4. DeleteAsset: Use the DelState method of the stub to delete the existing asset from the ledger.
+// When an admin has set the delete mode to soft (see SetDeleteMode), the asset is not deleted
+// but written back with status deleted, deletedBy and deletedAt:
+// DelState removes it for good, and whatever refers to its ID is left pointing at nothing.
+func (s *SimpleAssetChaincode) DeleteAsset(ctx contractapi.TransactionContextInterface, assetID string) error {
+
+	// Read the asset (and not only check that it exists), its owner and color
+	// tell which index entries have to be deleted with it
+	existing, err := s.QueryAsset(ctx, assetID)
+	if err != nil {
+		return err
+	}
+	// Only the owner may delete the asset
+	err = existing.CheckOwner(ctx.GetStub())
+	if err != nil {
+		return err
+	}
+
+	// A deleted asset can't be offered anymore, soft deleted or not
+	err = asset.DropPendingTransfer(ctx.GetStub(), assetID)
+	if err != nil {
+		return err
+	}
+
+	// The delete mode is read from the world state, so every endorsing peer deletes the same way
+	mode, err := asset.DeleteMode(ctx.GetStub())
+	if err != nil {
+		return err
+	}
+	if mode == asset.DeleteModeSoft {
+		before := *existing
+
+		// MarkDeleted fails if the asset is already deleted
+		err = existing.MarkDeleted(ctx.GetStub())
+		if err != nil {
+			return err
+		}
+		deletedAssetJSON, err := existing.Marshal()
+		if err != nil {
+			return err
+		}
+		err = ctx.GetStub().PutState(assetID, deletedAssetJSON)
+		if err != nil {
+			return fmt.Errorf("failed to mark asset as deleted in ledger: %v", err)
+		}
+
+		// A deleted asset is left out of the owner~id and color~id indexes
+		return s.recordChange(ctx, &before, existing)
+	}
+
+	// Delete asset from the ledger
+	err = ctx.GetStub().DelState(assetID)
+	if err != nil {
+		return fmt.Errorf("failed to delete asset from ledger: %v", err)
+	}
+
+	return s.recordChange(ctx, existing, nil)
+}
+
+// 4a. SetDeleteMode: Use the PutState method of the stub to store how DeleteAsset deletes, hard or soft.
+// Only a client with the attribute role=admin may change it. GetDeleteMode reads it back.
+func (s *SimpleAssetChaincode) SetDeleteMode(ctx contractapi.TransactionContextInterface, mode string) error {
+	return asset.SetDeleteMode(ctx.GetStub(), mode)
+}
+
+// GetDeleteMode returns the delete mode of the ledger, hard unless an admin set it to soft
+func (s *SimpleAssetChaincode) GetDeleteMode(ctx contractapi.TransactionContextInterface) (string, error) {
+	return asset.DeleteMode(ctx.GetStub())
+}
+
+// 4b. RestoreAsset: Use the PutState method of the stub to make a soft deleted asset active again.
+// Not everyone may undo a delete: only the client that deleted the asset, or a client whose certificate
+// has the attribute role=admin (see the cid lesson in Access_Control_7).
+func (s *SimpleAssetChaincode) RestoreAsset(ctx contractapi.TransactionContextInterface, assetID string) error {
+	existing, err := s.QueryAsset(ctx, assetID)
+	if err != nil {
+		return err
+	}
+	before := *existing
+
+	// Restore checks that the asset is deleted and that the client may restore it
+	err = existing.Restore(ctx.GetStub())
+	if err != nil {
+		return err
+	}
+	restoredAssetJSON, err := existing.Marshal()
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(assetID, restoredAssetJSON)
+	if err != nil {
+		return fmt.Errorf("failed to restore asset in ledger: %v", err)
+	}
+
+	// Put the asset back in the indexes
+	return s.recordChange(ctx, &before, existing)
+}
+
+
+// 5. AssetExists: Use the GetState method of the stub to check if an asset exists in the ledger.
+// (written in FirstHighLevel_2A.go)
+
+
+// 6. QueryAssetByID: Use the GetState method of the stub to retrieve the asset from the ledger by its ID.
+func (s *SimpleAssetChaincode) QueryAssetByID(ctx contractapi.TransactionContextInterface, assetID string) (string, error) {
+	// Retrieve asset from the ledger
+	assetJSON, err := ctx.GetStub().GetState(assetID)
+	if err != nil {
+		return "", fmt.Errorf("failed to read asset from ledger: %v", err)
+	}
+	if assetJSON == nil {
+		return "", fmt.Errorf("asset %s does not exist", assetID)
+	}
+
+	// Deserialize asset JSON
+	//(only required when you want to use this asset anywhere in this method, not in this example,
+	// QueryAsset in FirstHighLevel_2A.go does it)
+	//return asset.Unmarshal(assetJSON)
+
+	// As we return the stored json as it is, the return type is a string and not an Asset
+	return string(assetJSON), nil
+}
+
+
+// 7.QueryAllAssets: Use the GetStateByRange method of the stub to retrieve all assets from the ledger.
+// Soft deleted assets are left out, QueryDeletedAssets lists them.
+func (s *SimpleAssetChaincode) QueryAllAssets(ctx contractapi.TransactionContextInterface) ([]*Asset, error) {
+	// Retrieve all assets from the ledger, asset.QueryAll iterates over GetStateByRange("", "")
+	assets, err := asset.QueryAll(ctx.GetStub())
+	if err != nil {
+		return nil, err
+	}
+
+	return asset.ActiveOnly(assets), nil
+
+
+
+	// Learnings from this method:
+
+	// resultsIterator:
+	// The resultsIterator returned by GetStateByRange is indeed an iterator, not a storage variable.
+	// It does not store all key-value pairs in memory at once.
+	// Instead, it provides a mechanism to iterate over the key-value pairs one by one,
+	// fetching them from the database as needed. This approach is memory-efficient and
+	// allows you to process large datasets without consuming excessive memory.
+
+	// resultsIterator.Next():
+	// When you use resultsIterator.Next() in a loop, each call to Next() fetches the
+	// next key-value pair from the database. The iterator fetches key-value pairs lazily,
+	// meaning it retrieves them on-demand as you iterate over them.
+	// This can help reduce the memory footprint of your chaincode, especially when dealing with large datasets.
+}
+
+
+// 7a. QueryDeletedAssets: the soft deleted assets that QueryAllAssets hides, e.g. to pick one to restore
+func (s *SimpleAssetChaincode) QueryDeletedAssets(ctx contractapi.TransactionContextInterface) ([]*Asset, error) {
+	assets, err := asset.QueryAll(ctx.GetStub())
+	if err != nil {
+		return nil, err
+	}
+	return asset.DeletedOnly(assets), nil
+}
+
+
+// 7b. QueryAllAssetsWithPagination: QueryAllAssets loads every asset of the ledger in one response,
+// which gets slow (and eventually too big) once the ledger grows.
+// Use the GetStateByRangeWithPagination method of the stub to read one page of pageSize assets at a time instead.
+// The client passes an empty bookmark for the first page, then the bookmark of the page it got for the next one,
+// until the bookmark comes back empty.
+func (s *SimpleAssetChaincode) QueryAllAssetsWithPagination(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*asset.Page, error) {
+	return asset.QueryPage(ctx.GetStub(), pageSize, bookmark)
+}
+
+
+// 7c. QueryAssetsByOwner and QueryAssetsByColor: Use the GetStateByPartialCompositeKey method of the stub
+// to read the owner~id / color~id index instead of scanning every asset with GetStateByRange.
+// The indexes are composite keys kept up to date by every function that writes an asset (see recordChange).
+// The owner~id index is keyed by the owner identity, so owner is the display name of the owner among those of ownerMSPID.
+func (s *SimpleAssetChaincode) QueryAssetsByOwner(ctx contractapi.TransactionContextInterface, owner string, ownerMSPID string) ([]*Asset, error) {
+	return asset.QueryByOwner(ctx.GetStub(), owner, ownerMSPID)
+}
+
+func (s *SimpleAssetChaincode) QueryAssetsByColor(ctx contractapi.TransactionContextInterface, color string) ([]*Asset, error) {
+	return asset.QueryByIndex(ctx.GetStub(), asset.ColorIndex, color)
+}
+
+
+// 7d. QueryAssets: Use the GetQueryResult method of the stub to run a rich query, i.e. a CouchDB (Mango) selector
+// on the fields of the assets, e.g. {"owner": "Alice", "price": {"$gt": 100}}.
+// Unlike the queries above, rich queries only work when the peer uses CouchDB as state database (not LevelDB).
+// Without an index CouchDB scans every document, so the indexes in META-INF/statedb/couchdb/indexes
+// (owner, price, color) are packaged with the chaincode and created by the peer when it is installed.
+// Also note that rich queries are NOT re-executed at validation time, so a transaction that writes
+// based on one can't be protected against phantom reads: use them in query (evaluate) transactions.
+func (s *SimpleAssetChaincode) QueryAssets(ctx contractapi.TransactionContextInterface, selectorJSON string) ([]*Asset, error) {
+	return asset.QueryBySelector(ctx.GetStub(), selectorJSON)
+}
+
+// QueryAssetsWithPagination is QueryAssets one page at a time (see QueryAllAssetsWithPagination),
+// with the GetQueryResultWithPagination method of the stub
+func (s *SimpleAssetChaincode) QueryAssetsWithPagination(ctx contractapi.TransactionContextInterface, selectorJSON string, pageSize int32, bookmark string) (*asset.Page, error) {
+	return asset.QueryPageBySelector(ctx.GetStub(), selectorJSON, pageSize, bookmark)
+}
+
+
+// 8. GetHistoryForAsset: Use the GetHistoryForKey method of the stub to retrieve the transaction history for the asset by its ID.
+// Every write of the key is one entry (newest first): the transaction ID, its time, whether it deleted
+// the asset and, if not, the asset as that transaction wrote it. That's enough to replay the whole
+// lifecycle of the asset from a single call.
+func (s *SimpleAssetChaincode) GetHistoryForAsset(ctx contractapi.TransactionContextInterface, assetID string) ([]*TransactionHistory, error) {
+	return asset.QueryHistory(ctx.GetStub(), assetID, nil)
+}
+
+// GetHistoryForAssetInRange is GetHistoryForAsset restricted to the transactions from "from" to "to"
+// (RFC3339 times, e.g. 2024-01-31T00:00:00Z, leave one empty for no bound), and to the "limit" newest ones (0 for all).
+// contractapi has no optional parameters, that's why this is a second function.
+func (s *SimpleAssetChaincode) GetHistoryForAssetInRange(ctx contractapi.TransactionContextInterface, assetID string, from string, to string, limit int) ([]*TransactionHistory, error) {
+	filter, err := asset.ParseHistoryFilter(from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	return asset.QueryHistory(ctx.GetStub(), assetID, filter)
+}
+
+// 9. GetAssetChangeLog: Built on GetHistoryForKey as well, but instead of whole snapshots it returns,
+// per transaction, only the fields that changed (e.g. owner: Alice→Bob, price: 100→200),
+// and the ID of the client that submitted the transaction (recorded in the updatedBy field of the asset).
+// Oldest transaction first, so it reads like a log.
+func (s *SimpleAssetChaincode) GetAssetChangeLog(ctx contractapi.TransactionContextInterface, assetID string) ([]*asset.ChangeLogEntry, error) {
+	return asset.QueryChangeLog(ctx.GetStub(), assetID)
+}
+
+// TransactionHistory is one entry of the history of an asset (see asset.HistoryEntry)
+// The asset is returned decoded and not as the raw stored bytes, contractapi can not return
+// a []byte field anyway (its schema says array while encoding/json writes a base64 string)
+type TransactionHistory = asset.HistoryEntry
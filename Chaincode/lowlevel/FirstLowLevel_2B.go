@@ -0,0 +1,637 @@
+// Package lowlevel contains the SimpleAssetChaincode written with the low level shim api.
+package lowlevel
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+  	"github.com/hyperledger/fabric-chaincode-go/shim"
+  	pb "github.com/hyperledger/fabric-protos-go/peer"
+
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/asset"
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/assetevents"
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/router"
+)
+
+// SimpleAssetChaincode defines the Smart Contract structure
+type SimpleAssetChaincode struct {
+	// the router is built on the first Invoke, so new(SimpleAssetChaincode) is ready to use
+	routerOnce sync.Once
+	router     *router.Router
+}
+
+// Asset represents a single asset
+// It is the same asset.Asset that the high level chaincode (chapter 2A) stores
+type Asset = asset.Asset
+
+// INIT AND INVOKE
+
+// INIT (can be considered as a contructor for the contract)
+// When using the low level api for Go, both the Init and Invoke functions are essential.
+func (s *SimpleAssetChaincode) Init(stub shim.ChaincodeStubInterface) pb.Response {
+	// Initialize chaincode
+    // The Init function is invoked when the chaincode is instantiated or upgraded.
+    // It's typically used to perform any necessary initialization tasks, such as setting up
+    // initial state or configuration.
+	return shim.Success(nil)
+}
+
+// INVOKE (mediator for the external application and the custom functions of the contract)
+func (s *SimpleAssetChaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
+
+	// The Invoke function is the entry point for processing transactions in the chaincode.
+    // When a transaction is submitted to the chaincode, the Invoke function is called.
+    // Inside the Invoke function, the function name and parameters are extracted using
+    // ctx.GetStub().GetFunctionAndParameters().
+    // Then, based on the extracted function name, the transaction is routed
+    // to the appropriate handler function (e.g., CreateAsset, UpdateAsset, QueryAsset).
+	
+	// Instead of an if/else chain over the function name, every handler is registered
+	// once in a router together with its typed parameters (see routes below).
+	// The router checks the number of arguments and decodes them (strings as they are,
+	// an Asset from its json), so the handlers do not have to look at args anymore.
+	// An unknown function gets back a structured error listing the available functions.
+	s.routerOnce.Do(func() {
+		s.router = s.routes()
+	})
+
+	return s.router.Handle(stub)
+}
+
+// routes registers every function of the chaincode
+func (s *SimpleAssetChaincode) routes() *router.Router {
+	r := router.New()
+	// Assets sent by the client are decoded strictly: unknown fields, duplicate keys,
+	// trailing data and oversized payloads are rejected instead of silently accepted
+	r.Decode = asset.DecodeStrict
+	handlers := map[string]interface{}{
+		"CreateAsset":            s.CreateAsset,
+		"UpdateAsset":            s.UpdateAsset,
+		"QueryAsset":             s.QueryAsset,
+		"TransferAssetOwnership": s.TransferAssetOwnership,
+		"DeleteAsset":            s.DeleteAsset,
+		"RestoreAsset":           s.RestoreAsset,
+		"SetDeleteMode":          s.SetDeleteMode,
+		"GetDeleteMode":          s.GetDeleteMode,
+		"RegisterDisplayName":    s.RegisterDisplayName,
+		"AssignDisplayName":      s.AssignDisplayName,
+		"QueryDisplayName":       s.QueryDisplayName,
+		"GetHistoryForAsset":     s.GetHistoryForAsset,
+
+		"ProposeTransfer":              s.ProposeTransfer,
+		"AcceptTransfer":               s.AcceptTransfer,
+		"RejectTransfer":               s.RejectTransfer,
+		"QueryPendingTransfer":         s.QueryPendingTransfer,
+		"GetHistoryForAssetInRange":    s.GetHistoryForAssetInRange,
+		"GetAssetChangeLog":            s.GetAssetChangeLog,
+		"QueryAllAssetsWithPagination": s.QueryAllAssetsWithPagination,
+		"QueryDeletedAssets":           s.QueryDeletedAssets,
+		"QueryAssetsByOwner":           s.QueryAssetsByOwner,
+		"QueryAssetsByColor":           s.QueryAssetsByColor,
+		"QueryAssets":                  s.QueryAssets,
+		"QueryAssetsWithPagination":    s.QueryAssetsWithPagination,
+	}
+	for name, handler := range handlers {
+		err := r.Register(name, handler)
+		if err != nil {
+			// only happens if a handler above has a wrong signature
+			panic(err)
+		}
+	}
+	return r
+}
+
+func (s *SimpleAssetChaincode) CreateAsset(stub shim.ChaincodeStubInterface, newAsset Asset) pb.Response {
+	// asset.Create creates the asset the way the high level CreateAsset does: it validates it,
+	// rejects an ID that exists already, binds the owner to the client and stores the
+	// re-serialized asset with its indexes, so the document has the same shape as the one of chapter 2A
+	err := asset.Create(stub, &newAsset)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	err = assetevents.EmitChange(stub, nil, &newAsset)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return shim.Success(nil)
+}
+
+func (s *SimpleAssetChaincode) AssetExists(stub shim.ChaincodeStubInterface, assetID string) (bool, error) {
+	assetBytes, err := stub.GetState(assetID)
+	if err != nil {
+		return false, fmt.Errorf("Failed to read from world state: %v", err)
+	}
+	return assetBytes != nil, nil
+}
+
+func (s *SimpleAssetChaincode) UpdateAsset(stub shim.ChaincodeStubInterface, updatedAsset Asset) pb.Response {
+	err := updatedAsset.Validate()
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	err = updatedAsset.CheckClientLifecycle()
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	existingBytes, err := stub.GetState(updatedAsset.ID)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to read asset %s from world state: %v", updatedAsset.ID, err))
+	}
+	if existingBytes == nil {
+		return shim.Error(fmt.Sprintf("Asset %s does not exist", updatedAsset.ID))
+	}
+	existing, err := asset.Unmarshal(existingBytes)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	err = existing.CheckNotDeleted()
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	err = updatedAsset.CheckUpdate(stub, existing)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	updatedAsset.Version = existing.Version + 1
+	updatedAsset.UpdatedBy = asset.SubmitterID(stub)
+	assetJSON, err := updatedAsset.Marshal()
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	err = stub.PutState(updatedAsset.ID, assetJSON)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to update asset: %s", err))
+	}
+
+	err = s.recordChange(stub, existing, &updatedAsset)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return shim.Success(nil)
+}
+
+func (s *SimpleAssetChaincode) QueryAsset(stub shim.ChaincodeStubInterface, assetID string) pb.Response {
+	assetBytes, err := stub.GetState(assetID)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to read asset %s from world state: %v", assetID, err))
+	}
+	if assetBytes == nil {
+		return shim.Error(fmt.Sprintf("Asset %s does not exist", assetID))
+	}
+
+	return shim.Success(assetBytes)
+}
+
+func (s *SimpleAssetChaincode) TransferAssetOwnership(stub shim.ChaincodeStubInterface, assetID string, newOwner string, newOwnerMSPID string) pb.Response {
+	assetBytes, err := stub.GetState(assetID)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to read asset %s from world state: %v", assetID, err))
+	}
+	if assetBytes == nil {
+		return shim.Error(fmt.Sprintf("Asset %s does not exist", assetID))
+	}
+	existing, err := asset.Unmarshal(assetBytes)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	err = existing.CheckNotDeleted()
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	err = existing.CheckOwner(stub)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	recipient, err := asset.ResolveDisplayName(stub, newOwner, newOwnerMSPID)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	before := *existing
+	existing.SetOwner(recipient)
+	existing.Version++
+	existing.UpdatedBy = asset.SubmitterID(stub)
+	err = existing.Validate()
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	assetJSON, err := existing.Marshal()
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	err = stub.PutState(existing.ID, assetJSON)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to transfer asset: %s", err))
+	}
+	err = asset.DropPendingTransfer(stub, existing.ID)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	err = s.recordChange(stub, &before, existing)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return shim.Success(nil)
+}
+
+// RegisterDisplayName maps the display name to the client identity of the caller,
+// see RegisterDisplayName of the high level chaincode
+func (s *SimpleAssetChaincode) RegisterDisplayName(stub shim.ChaincodeStubInterface, name string) pb.Response {
+	displayName, err := asset.RegisterDisplayName(stub, name)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return s.displayNameResponse(displayName)
+}
+
+// AssignDisplayName maps the display name to the client identity mspID and clientID,
+// see AssignDisplayName of the high level chaincode
+func (s *SimpleAssetChaincode) AssignDisplayName(stub shim.ChaincodeStubInterface, name string, mspID string, clientID string) pb.Response {
+	displayName, err := asset.AssignDisplayName(stub, name, mspID, clientID)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return s.displayNameResponse(displayName)
+}
+
+// QueryDisplayName returns the client identity a display name of mspID maps to
+func (s *SimpleAssetChaincode) QueryDisplayName(stub shim.ChaincodeStubInterface, name string, mspID string) pb.Response {
+	displayName, err := asset.ResolveDisplayName(stub, name, mspID)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return s.displayNameResponse(displayName)
+}
+
+func (s *SimpleAssetChaincode) displayNameResponse(displayName *asset.DisplayName) pb.Response {
+	displayNameJSON, err := json.Marshal(displayName)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to marshal display name: %v", err))
+	}
+	return shim.Success(displayNameJSON)
+}
+
+// ProposeTransfer offers the asset to newOwner of newOwnerMSPID for validFor (e.g. "24h"), only its owner may do so.
+// The asset changes hands when newOwner calls AcceptTransfer, see the two-phase transfer of the high level chaincode.
+func (s *SimpleAssetChaincode) ProposeTransfer(stub shim.ChaincodeStubInterface, assetID string, newOwner string, newOwnerMSPID string, validFor string) pb.Response {
+	transfer, err := asset.ProposeTransfer(stub, assetID, newOwner, newOwnerMSPID, validFor)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	err = emitTransferEvent(stub, assetevents.NameTransferProposed, transfer)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return s.transferResponse(transfer)
+}
+
+// AcceptTransfer makes the recipient of the pending transfer (the caller) the owner of the asset
+func (s *SimpleAssetChaincode) AcceptTransfer(stub shim.ChaincodeStubInterface, assetID string) pb.Response {
+	transfer, before, transferred, err := asset.AcceptTransfer(stub, assetID)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	// A transaction has a single event: AssetTransferred and TransferAccepted go in one AssetEvents envelope
+	events := assetevents.Accumulator{}
+	change, err := assetevents.NewChange(stub, before, transferred)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	events.Raise(change)
+	accepted, err := assetevents.NewTransfer(stub, assetevents.NameTransferAccepted, transfer)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	events.Raise(accepted)
+	err = events.Flush(stub)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	assetJSON, err := json.Marshal(transferred)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to marshal asset: %v", err))
+	}
+	return shim.Success(assetJSON)
+}
+
+// RejectTransfer drops the pending transfer, only its recipient may do so
+func (s *SimpleAssetChaincode) RejectTransfer(stub shim.ChaincodeStubInterface, assetID string) pb.Response {
+	transfer, err := asset.RejectTransfer(stub, assetID)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	err = emitTransferEvent(stub, assetevents.NameTransferRejected, transfer)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(nil)
+}
+
+// QueryPendingTransfer returns the pending transfer of the asset
+func (s *SimpleAssetChaincode) QueryPendingTransfer(stub shim.ChaincodeStubInterface, assetID string) pb.Response {
+	transfer, err := asset.QueryPendingTransfer(stub, assetID)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return s.transferResponse(transfer)
+}
+
+// emitTransferEvent emits the event name of a step of the two-phase transfer
+func emitTransferEvent(stub shim.ChaincodeStubInterface, name string, transfer *asset.PendingTransfer) error {
+	event, err := assetevents.NewTransfer(stub, name, transfer)
+	if err != nil {
+		return err
+	}
+	return assetevents.Emit(stub, event)
+}
+
+func (s *SimpleAssetChaincode) transferResponse(transfer *asset.PendingTransfer) pb.Response {
+	transferJSON, err := json.Marshal(transfer)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to marshal pending transfer: %v", err))
+	}
+	return shim.Success(transferJSON)
+}
+
+func (s *SimpleAssetChaincode) DeleteAsset(stub shim.ChaincodeStubInterface, assetID string) pb.Response {
+	existing, errResponse := s.readAsset(stub, assetID)
+	if errResponse != nil {
+		return *errResponse
+	}
+	err := existing.CheckOwner(stub)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	err = asset.DropPendingTransfer(stub, assetID)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	// The delete mode is read from the world state, so every endorsing peer deletes the same way
+	mode, err := asset.DeleteMode(stub)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if mode == asset.DeleteModeSoft {
+		before := *existing
+		err = existing.MarkDeleted(stub)
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		return s.putAsset(stub, &before, existing)
+	}
+
+	err = stub.DelState(assetID)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to delete asset: %s", err))
+	}
+
+	err = s.recordChange(stub, existing, nil)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return shim.Success(nil)
+}
+
+// SetDeleteMode stores how DeleteAsset deletes, hard or soft, see the high level SetDeleteMode.
+// Only a client with the attribute role=admin may change it.
+func (s *SimpleAssetChaincode) SetDeleteMode(stub shim.ChaincodeStubInterface, mode string) pb.Response {
+	err := asset.SetDeleteMode(stub, mode)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(nil)
+}
+
+// GetDeleteMode returns the delete mode of the ledger
+func (s *SimpleAssetChaincode) GetDeleteMode(stub shim.ChaincodeStubInterface) pb.Response {
+	mode, err := asset.DeleteMode(stub)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success([]byte(mode))
+}
+
+// RestoreAsset makes a soft deleted asset active again.
+// Only the client that deleted it, or a client with the attribute role=admin, may restore it.
+func (s *SimpleAssetChaincode) RestoreAsset(stub shim.ChaincodeStubInterface, assetID string) pb.Response {
+	existing, errResponse := s.readAsset(stub, assetID)
+	if errResponse != nil {
+		return *errResponse
+	}
+
+	before := *existing
+	err := existing.Restore(stub)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return s.putAsset(stub, &before, existing)
+}
+
+// recordChange moves the asset in the indexes and emits the typed event of its write,
+// see recordChange of the high level chaincode
+func (s *SimpleAssetChaincode) recordChange(stub shim.ChaincodeStubInterface, before *Asset, after *Asset) error {
+	err := asset.UpdateIndexes(stub, before, after)
+	if err != nil {
+		return err
+	}
+	return assetevents.EmitChange(stub, before, after)
+}
+
+// readAsset reads and decodes the stored asset, or returns the error response to send back
+func (s *SimpleAssetChaincode) readAsset(stub shim.ChaincodeStubInterface, assetID string) (*Asset, *pb.Response) {
+	assetBytes, err := stub.GetState(assetID)
+	if err != nil {
+		response := shim.Error(fmt.Sprintf("Failed to read asset %s from world state: %v", assetID, err))
+		return nil, &response
+	}
+	if assetBytes == nil {
+		response := shim.Error(fmt.Sprintf("Asset %s does not exist", assetID))
+		return nil, &response
+	}
+	existing, err := asset.Unmarshal(assetBytes)
+	if err != nil {
+		response := shim.Error(err.Error())
+		return nil, &response
+	}
+	return existing, nil
+}
+
+// putAsset writes the asset changed from before to after, and moves it in the indexes
+func (s *SimpleAssetChaincode) putAsset(stub shim.ChaincodeStubInterface, before *Asset, after *Asset) pb.Response {
+	assetJSON, err := after.Marshal()
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	err = stub.PutState(after.ID, assetJSON)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to write asset: %s", err))
+	}
+
+	err = s.recordChange(stub, before, after)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return shim.Success(nil)
+}
+
+// QueryAllAssetsWithPagination returns one page of at most pageSize assets, starting at bookmark.
+// It is the same query as in the high level chaincode, the page is returned as json:
+// {"records": [...], "fetchedCount": 2, "bookmark": "asset3"}
+func (s *SimpleAssetChaincode) QueryAllAssetsWithPagination(stub shim.ChaincodeStubInterface, pageSize int32, bookmark string) pb.Response {
+	page, err := asset.QueryPage(stub, pageSize, bookmark)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	pageJSON, err := json.Marshal(page)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to marshal page: %v", err))
+	}
+
+	return shim.Success(pageJSON)
+}
+
+// QueryDeletedAssets returns the soft deleted assets, which the other queries leave out
+func (s *SimpleAssetChaincode) QueryDeletedAssets(stub shim.ChaincodeStubInterface) pb.Response {
+	assets, err := asset.QueryAll(stub)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	assetsJSON, err := json.Marshal(asset.DeletedOnly(assets))
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to marshal assets: %v", err))
+	}
+
+	return shim.Success(assetsJSON)
+}
+
+// QueryAssetsByOwner returns the assets of the owner with the display name owner of ownerMSPID,
+// read through the owner~id index, which is keyed by the owner identity
+func (s *SimpleAssetChaincode) QueryAssetsByOwner(stub shim.ChaincodeStubInterface, owner string, ownerMSPID string) pb.Response {
+	assets, err := asset.QueryByOwner(stub, owner, ownerMSPID)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return s.assetsResponse(assets)
+}
+
+// QueryAssetsByColor returns the assets of the given color, read through the color~id index
+func (s *SimpleAssetChaincode) QueryAssetsByColor(stub shim.ChaincodeStubInterface, color string) pb.Response {
+	return s.queryByIndex(stub, asset.ColorIndex, color)
+}
+
+// QueryAssets returns the assets matching a CouchDB selector, e.g. {"owner": "Alice"}.
+// It needs CouchDB as state database, see QueryAssets of the high level chaincode.
+func (s *SimpleAssetChaincode) QueryAssets(stub shim.ChaincodeStubInterface, selectorJSON string) pb.Response {
+	assets, err := asset.QueryBySelector(stub, selectorJSON)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	assetsJSON, err := json.Marshal(assets)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to marshal assets: %v", err))
+	}
+
+	return shim.Success(assetsJSON)
+}
+
+// QueryAssetsWithPagination returns one page of the assets matching a CouchDB selector
+func (s *SimpleAssetChaincode) QueryAssetsWithPagination(stub shim.ChaincodeStubInterface, selectorJSON string, pageSize int32, bookmark string) pb.Response {
+	page, err := asset.QueryPageBySelector(stub, selectorJSON, pageSize, bookmark)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	pageJSON, err := json.Marshal(page)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to marshal page: %v", err))
+	}
+
+	return shim.Success(pageJSON)
+}
+
+func (s *SimpleAssetChaincode) queryByIndex(stub shim.ChaincodeStubInterface, index string, value string) pb.Response {
+	assets, err := asset.QueryByIndex(stub, index, value)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return s.assetsResponse(assets)
+}
+
+func (s *SimpleAssetChaincode) assetsResponse(assets []*Asset) pb.Response {
+	assetsJSON, err := json.Marshal(assets)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to marshal assets: %v", err))
+	}
+
+	return shim.Success(assetsJSON)
+}
+
+// TransactionHistory is one entry of the history of an asset: the asset as written by
+// the transaction (nothing for a delete), the isDelete flag and the RFC3339 time of the transaction
+type TransactionHistory = asset.HistoryEntry
+
+func (s *SimpleAssetChaincode) GetHistoryForAsset(stub shim.ChaincodeStubInterface, assetID string) pb.Response {
+	return s.history(stub, assetID, nil)
+}
+
+// GetHistoryForAssetInRange returns the history between the RFC3339 times from and to
+// (either can be empty) and at most limit entries (0 for all), newest first
+func (s *SimpleAssetChaincode) GetHistoryForAssetInRange(stub shim.ChaincodeStubInterface, assetID string, from string, to string, limit int) pb.Response {
+	filter, err := asset.ParseHistoryFilter(from, to, limit)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return s.history(stub, assetID, filter)
+}
+
+// GetAssetChangeLog returns, per transaction (oldest first), the fields it changed in the asset
+// and the ID of the client that submitted it
+func (s *SimpleAssetChaincode) GetAssetChangeLog(stub shim.ChaincodeStubInterface, assetID string) pb.Response {
+	changeLog, err := asset.QueryChangeLog(stub, assetID)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	changeLogJSON, err := json.Marshal(changeLog)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to marshal change log: %v", err))
+	}
+
+	return shim.Success(changeLogJSON)
+}
+
+func (s *SimpleAssetChaincode) history(stub shim.ChaincodeStubInterface, assetID string, filter *asset.HistoryFilter) pb.Response {
+	history, err := asset.QueryHistory(stub, assetID, filter)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	historyJSON, err := json.Marshal(history)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to marshal history: %v", err))
+	}
+
+	return shim.Success(historyJSON)
+}
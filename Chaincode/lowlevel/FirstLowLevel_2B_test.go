@@ -0,0 +1,500 @@
+package lowlevel
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/asset"
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/assetevents"
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/chaincodetest"
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/router"
+)
+
+const asset1JSON = `{"ID":"asset1","owner":"Alice","color":"red","size":5,"price":100}`
+
+func invoke(stub *chaincodetest.Stub, txID string, args ...string) (int32, string, []byte) {
+	byteArgs := make([][]byte, 0, len(args))
+	for _, arg := range args {
+		byteArgs = append(byteArgs, []byte(arg))
+	}
+	response := stub.MockInvoke(txID, byteArgs)
+	return response.Status, response.Message, response.Payload
+}
+
+// newStub creates a stub that runs every transaction as Alice, the owner of the assets she creates
+func newStub(t *testing.T, cc *SimpleAssetChaincode) *chaincodetest.Stub {
+	t.Helper()
+	stub := chaincodetest.NewStub("asset", cc)
+	setCreator(t, stub, "Alice")
+	return stub
+}
+
+// as makes a setup step of TestInvoke run as client (e.g. Bob) instead of Alice
+func as(client string, args ...string) []string {
+	return append([]string{"as:" + client}, args...)
+}
+
+// setCreator makes the client of Org1MSP with the common name client the creator of the next transactions
+func setCreator(t *testing.T, stub *chaincodetest.Stub, client string) {
+	t.Helper()
+	ci, err := chaincodetest.NewClientIdentity("Org1MSP", client, nil)
+	if err != nil {
+		t.Fatalf("failed to create identity: %v", err)
+	}
+	if err := stub.SetCreator(ci); err != nil {
+		t.Fatalf("failed to set creator: %v", err)
+	}
+}
+
+func TestInvoke(t *testing.T) {
+	tests := []struct {
+		name        string
+		setup       [][]string
+		args        []string
+		wantStatus  int32
+		wantMessage string
+		check       func(t *testing.T, payload []byte)
+	}{
+		{
+			name:       "create",
+			args:       []string{"CreateAsset", asset1JSON},
+			wantStatus: shim.OK,
+		},
+		{
+			name:        "create duplicate",
+			setup:       [][]string{{"CreateAsset", asset1JSON}},
+			args:        []string{"CreateAsset", asset1JSON},
+			wantStatus:  shim.ERROR,
+			wantMessage: "the asset asset1 already exists",
+		},
+		{
+			name:        "create invalid",
+			args:        []string{"CreateAsset", `{"ID":"asset1","color":"red","size":5,"price":100}`},
+			wantStatus:  shim.ERROR,
+			wantMessage: "asset owner is required",
+		},
+		{
+			name:        "create unknown field",
+			args:        []string{"CreateAsset", `{"ID":"asset1","owner":"Alice","color":"red","size":5,"price":100,"admin":true}`},
+			wantStatus:  shim.ERROR,
+			wantMessage: `unknown field \"admin\"`,
+		},
+		{
+			name:        "create wrong number of arguments",
+			args:        []string{"CreateAsset"},
+			wantStatus:  shim.ERROR,
+			wantMessage: router.CodeWrongArgumentCount,
+		},
+		{
+			name:       "query",
+			setup:      [][]string{{"CreateAsset", asset1JSON}},
+			args:       []string{"QueryAsset", "asset1"},
+			wantStatus: shim.OK,
+			check: func(t *testing.T, payload []byte) {
+				want := `{"ID":"asset1","color":"red","docType":"asset","owner":"Alice","ownerId":"eDUwOTo6Q049QWxpY2U6OkNOPUFsaWNl","ownerMspId":"Org1MSP",` +
+					`"price":100,"size":5,"updatedBy":"eDUwOTo6Q049QWxpY2U6OkNOPUFsaWNl","version":1}`
+				if string(payload) != want {
+					t.Errorf("payload = %s, want %s", payload, want)
+				}
+			},
+		},
+		{
+			name:        "query missing",
+			args:        []string{"QueryAsset", "asset1"},
+			wantStatus:  shim.ERROR,
+			wantMessage: "Asset asset1 does not exist",
+		},
+		{
+			name:       "update",
+			setup:      [][]string{{"CreateAsset", asset1JSON}, {"UpdateAsset", `{"ID":"asset1","owner":"Alice","color":"blue","size":5,"price":150}`}},
+			args:       []string{"QueryAsset", "asset1"},
+			wantStatus: shim.OK,
+			check: func(t *testing.T, payload []byte) {
+				a := unmarshalAsset(t, payload)
+				if a.Color != "blue" || a.Price != 150 || a.Version != 2 {
+					t.Errorf("asset = %+v, want color blue, price 150, version 2", a)
+				}
+			},
+		},
+		{
+			name:        "update missing",
+			args:        []string{"UpdateAsset", asset1JSON},
+			wantStatus:  shim.ERROR,
+			wantMessage: "Asset asset1 does not exist",
+		},
+		{
+			name:       "transfer",
+			setup:      [][]string{{"CreateAsset", asset1JSON}, as("Bob", "RegisterDisplayName", "Bob"), {"TransferAssetOwnership", "asset1", "Bob", "Org1MSP"}},
+			args:       []string{"QueryAsset", "asset1"},
+			wantStatus: shim.OK,
+			check: func(t *testing.T, payload []byte) {
+				a := unmarshalAsset(t, payload)
+				if a.Owner != "Bob" || a.Version != 2 {
+					t.Errorf("asset = %+v, want owner Bob, version 2", a)
+				}
+			},
+		},
+		{
+			name:        "transfer to nobody",
+			setup:       [][]string{{"CreateAsset", asset1JSON}},
+			args:        []string{"TransferAssetOwnership", "asset1", "", "Org1MSP"},
+			wantStatus:  shim.ERROR,
+			wantMessage: "no client of Org1MSP registered the display name",
+		},
+		{
+			name:        "transfer missing",
+			args:        []string{"TransferAssetOwnership", "asset1", "Bob", "Org1MSP"},
+			wantStatus:  shim.ERROR,
+			wantMessage: "Asset asset1 does not exist",
+		},
+		{
+			name:        "delete",
+			setup:       [][]string{{"CreateAsset", asset1JSON}, {"DeleteAsset", "asset1"}},
+			args:        []string{"QueryAsset", "asset1"},
+			wantStatus:  shim.ERROR,
+			wantMessage: "Asset asset1 does not exist",
+		},
+		{
+			name:        "delete missing",
+			args:        []string{"DeleteAsset", "asset1"},
+			wantStatus:  shim.ERROR,
+			wantMessage: "Asset asset1 does not exist",
+		},
+		{
+			name: "query page",
+			setup: [][]string{
+				{"CreateAsset", asset1JSON},
+				as("Bob", "CreateAsset", `{"ID":"asset2","owner":"Bob","color":"blue","size":10,"price":200}`),
+			},
+			args:       []string{"QueryAllAssetsWithPagination", "1", ""},
+			wantStatus: shim.OK,
+			check: func(t *testing.T, payload []byte) {
+				var page asset.Page
+				if err := json.Unmarshal(payload, &page); err != nil {
+					t.Fatalf("failed to unmarshal page: %v", err)
+				}
+				if page.FetchedCount != 1 || page.Records[0].ID != "asset1" || page.Bookmark != "asset2" {
+					t.Errorf("page = %s, want asset1 with bookmark asset2", payload)
+				}
+			},
+		},
+		{
+			name: "query by owner after transfer",
+			setup: [][]string{
+				{"CreateAsset", asset1JSON},
+				as("Bob", "CreateAsset", `{"ID":"asset2","owner":"Bob","color":"blue","size":10,"price":200}`),
+				as("Bob", "TransferAssetOwnership", "asset2", "Alice", "Org1MSP"),
+			},
+			args:       []string{"QueryAssetsByOwner", "Alice", "Org1MSP"},
+			wantStatus: shim.OK,
+			check: func(t *testing.T, payload []byte) {
+				var assets []*Asset
+				if err := json.Unmarshal(payload, &assets); err != nil {
+					t.Fatalf("failed to unmarshal assets: %v", err)
+				}
+				if len(assets) != 2 || assets[0].ID != "asset1" || assets[1].ID != "asset2" {
+					t.Errorf("assets of Alice = %s, want asset1 and asset2", payload)
+				}
+			},
+		},
+		{
+			name:       "query by color after delete",
+			setup:      [][]string{{"CreateAsset", asset1JSON}, {"DeleteAsset", "asset1"}},
+			args:       []string{"QueryAssetsByColor", "red"},
+			wantStatus: shim.OK,
+			check: func(t *testing.T, payload []byte) {
+				if string(payload) != "[]" {
+					t.Errorf("red assets = %s, want none", payload)
+				}
+			},
+		},
+		{
+			name:       "rich query",
+			setup:      [][]string{{"CreateAsset", asset1JSON}},
+			args:       []string{"QueryAssets", `{"color":"red"}`},
+			wantStatus: shim.OK,
+			check: func(t *testing.T, payload []byte) {
+				var assets []*Asset
+				if err := json.Unmarshal(payload, &assets); err != nil {
+					t.Fatalf("failed to unmarshal assets: %v", err)
+				}
+				if len(assets) != 1 || assets[0].ID != "asset1" {
+					t.Errorf("red assets = %s, want asset1", payload)
+				}
+			},
+		},
+		{
+			name: "history",
+			setup: [][]string{
+				{"CreateAsset", asset1JSON},
+				as("Bob", "RegisterDisplayName", "Bob"),
+				{"TransferAssetOwnership", "asset1", "Bob", "Org1MSP"},
+				as("Bob", "DeleteAsset", "asset1"),
+			},
+			args:       []string{"GetHistoryForAsset", "asset1"},
+			wantStatus: shim.OK,
+			check: func(t *testing.T, payload []byte) {
+				var history []*TransactionHistory
+				if err := json.Unmarshal(payload, &history); err != nil {
+					t.Fatalf("failed to unmarshal history: %v", err)
+				}
+				if len(history) != 3 {
+					t.Fatalf("got %d history entries, want 3", len(history))
+				}
+				wantTxIDs := []string{"setup3", "setup2", "setup0"}
+				for i, entry := range history {
+					if entry.TxId != wantTxIDs[i] {
+						t.Errorf("history[%d].TxId = %s, want %s", i, entry.TxId, wantTxIDs[i])
+					}
+				}
+				if !history[0].IsDelete || history[0].Asset != nil {
+					t.Errorf("delete entry = %+v, want isDelete and no asset", history[0])
+				}
+				if history[1].IsDelete || history[1].Asset == nil || history[1].Asset.Owner != "Bob" {
+					t.Errorf("transfer entry = %+v, want the asset of Bob", history[1])
+				}
+			},
+		},
+		{
+			name: "change log",
+			setup: [][]string{
+				{"CreateAsset", asset1JSON},
+				{"UpdateAsset", `{"ID":"asset1","owner":"Alice","color":"blue","size":5,"price":100}`},
+			},
+			args:       []string{"GetAssetChangeLog", "asset1"},
+			wantStatus: shim.OK,
+			check: func(t *testing.T, payload []byte) {
+				var changeLog []*asset.ChangeLogEntry
+				if err := json.Unmarshal(payload, &changeLog); err != nil {
+					t.Fatalf("failed to unmarshal change log: %v", err)
+				}
+				if len(changeLog) != 2 || changeLog[0].Action != asset.ActionCreated {
+					t.Fatalf("change log = %s, want a create and an update", payload)
+				}
+				update := changeLog[1]
+				if update.ClientID == "" || len(update.Changes) != 1 || update.Changes[0].String() != "color: red→blue" {
+					t.Errorf("update = %+v, want only color: red→blue by Alice", update)
+				}
+			},
+		},
+		{
+			name:        "unknown function",
+			args:        []string{"BurnAsset", "asset1"},
+			wantStatus:  shim.ERROR,
+			wantMessage: router.CodeUnknownFunction,
+		},
+		{
+			name:        "create malformed json",
+			args:        []string{"CreateAsset", `{"ID":`},
+			wantStatus:  shim.ERROR,
+			wantMessage: router.CodeInvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := newStub(t, new(SimpleAssetChaincode))
+			for i, args := range tt.setup {
+				if client, ok := strings.CutPrefix(args[0], "as:"); ok {
+					setCreator(t, stub, client)
+					args = args[1:]
+				}
+				status, message, _ := invoke(stub, fmt.Sprintf("setup%d", i), args...)
+				if status != shim.OK {
+					t.Fatalf("setup %v failed: %s", args, message)
+				}
+				setCreator(t, stub, "Alice")
+			}
+
+			status, message, payload := invoke(stub, "tx", tt.args...)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d (%s), want %d", status, message, tt.wantStatus)
+			}
+			if !strings.Contains(message, tt.wantMessage) {
+				t.Errorf("message = %q, want it to contain %q", message, tt.wantMessage)
+			}
+			if tt.check != nil {
+				tt.check(t, payload)
+			}
+		})
+	}
+}
+
+func unmarshalAsset(t *testing.T, payload []byte) *Asset {
+	t.Helper()
+	var a Asset
+	if err := json.Unmarshal(payload, &a); err != nil {
+		t.Fatalf("failed to unmarshal asset %s: %v", payload, err)
+	}
+	return &a
+}
+
+func TestInvokeUnknownFunctionListsFunctions(t *testing.T) {
+	stub := chaincodetest.NewStub("asset", new(SimpleAssetChaincode))
+	_, message, _ := invoke(stub, "tx", "BurnAsset", "asset1")
+
+	routerErr, ok := router.ParseError(message)
+	if !ok {
+		t.Fatalf("message %q is not a router error", message)
+	}
+	available := strings.Join(routerErr.Available, ",")
+	for _, function := range []string{"CreateAsset", "QueryAsset", "TransferAssetOwnership"} {
+		if !strings.Contains(available, function) {
+			t.Errorf("available = %v, want it to list %s", routerErr.Available, function)
+		}
+	}
+}
+
+func TestSoftDeleteAndRestore(t *testing.T) {
+	alice, err := chaincodetest.NewClientIdentity("Org1MSP", "alice", nil)
+	if err != nil {
+		t.Fatalf("failed to create identity: %v", err)
+	}
+	admin, err := chaincodetest.NewClientIdentity("Org1MSP", "admin", map[string]string{"role": asset.AdminRole})
+	if err != nil {
+		t.Fatalf("failed to create identity: %v", err)
+	}
+	stub := chaincodetest.NewStub("asset", new(SimpleAssetChaincode))
+
+	// only an admin may make the deletes soft
+	if err := stub.SetCreator(alice); err != nil {
+		t.Fatalf("failed to set creator: %v", err)
+	}
+	if status, _, _ := invoke(stub, "mode by alice", "SetDeleteMode", "soft"); status == shim.OK {
+		t.Errorf("SetDeleteMode by alice succeeded")
+	}
+	if err := stub.SetCreator(admin); err != nil {
+		t.Fatalf("failed to set creator: %v", err)
+	}
+	if status, message, _ := invoke(stub, "mode", "SetDeleteMode", "soft"); status != shim.OK {
+		t.Fatalf("SetDeleteMode failed: %s", message)
+	}
+	if _, _, mode := invoke(stub, "get mode", "GetDeleteMode"); string(mode) != asset.DeleteModeSoft {
+		t.Errorf("GetDeleteMode = %s, want %s", mode, asset.DeleteModeSoft)
+	}
+	if err := stub.SetCreator(alice); err != nil {
+		t.Fatalf("failed to set creator: %v", err)
+	}
+
+	for i, args := range [][]string{
+		{"CreateAsset", asset1JSON},
+		{"DeleteAsset", "asset1"},
+	} {
+		if status, message, _ := invoke(stub, fmt.Sprintf("setup%d", i), args...); status != shim.OK {
+			t.Fatalf("setup %v failed: %s", args, message)
+		}
+	}
+
+	status, message, payload := invoke(stub, "deleted", "QueryDeletedAssets")
+	if status != shim.OK || !strings.Contains(string(payload), `"deletedBy":"`+alice.ID+`"`) {
+		t.Fatalf("QueryDeletedAssets = %d (%s) %s, want asset1 deleted by alice", status, message, payload)
+	}
+
+	// alice deleted the asset, so she may restore it without the admin role
+	if status, message, _ := invoke(stub, "restore", "RestoreAsset", "asset1"); status != shim.OK {
+		t.Fatalf("RestoreAsset failed: %s", message)
+	}
+	_, _, payload = invoke(stub, "query", "QueryAsset", "asset1")
+	if restored := unmarshalAsset(t, payload); restored.Status != asset.StatusActive || restored.Version != 3 {
+		t.Errorf("restored asset = %+v, want active at version 3", restored)
+	}
+}
+
+func TestTwoPhaseTransfer(t *testing.T) {
+	clients := map[string]*chaincodetest.ClientIdentity{}
+	for _, name := range []string{"Alice", "Bob", "Carol"} {
+		ci, err := chaincodetest.NewClientIdentity("Org1MSP", name, nil)
+		if err != nil {
+			t.Fatalf("failed to create identity: %v", err)
+		}
+		clients[name] = ci
+	}
+	stub := chaincodetest.NewStub("asset", new(SimpleAssetChaincode))
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+	stub.Clock = func() time.Time { return now }
+
+	tests := []struct {
+		name        string
+		client      string
+		args        []string
+		advance     time.Duration
+		wantMessage string
+		wantOwner   string // the owner of asset1 after the transaction, if checked
+		wantEvents  string // the names of the asset events of the transaction, if checked
+	}{
+		{name: "create", client: "Alice", args: []string{"CreateAsset", asset1JSON}},
+		{name: "register Bob", client: "Bob", args: []string{"RegisterDisplayName", "Bob"}},
+		{name: "register Carol", client: "Carol", args: []string{"RegisterDisplayName", "Carol"}},
+		{name: "propose wrong number of arguments", client: "Alice", args: []string{"ProposeTransfer", "asset1", "Bob"}, wantMessage: router.CodeWrongArgumentCount},
+		{name: "propose by non owner", client: "Bob", args: []string{"ProposeTransfer", "asset1", "Bob", "Org1MSP", "1h"}, wantMessage: "is not the owner of the asset asset1"},
+		{name: "propose", client: "Alice", args: []string{"ProposeTransfer", "asset1", "Bob", "Org1MSP", "1h"}, wantEvents: assetevents.NameTransferProposed},
+		{name: "propose while pending", client: "Alice", args: []string{"ProposeTransfer", "asset1", "Carol", "Org1MSP", "1h"}, wantMessage: "already has a pending transfer to Bob"},
+		{name: "reject by other client", client: "Carol", args: []string{"RejectTransfer", "asset1"}, wantMessage: "is not the recipient of the transfer"},
+		{name: "reject", client: "Bob", args: []string{"RejectTransfer", "asset1"}, wantEvents: assetevents.NameTransferRejected},
+		{name: "accept rejected", client: "Bob", args: []string{"AcceptTransfer", "asset1"}, wantMessage: "has no pending transfer"},
+		{name: "propose again", client: "Alice", args: []string{"ProposeTransfer", "asset1", "Bob", "Org1MSP", "1h"}},
+		// handing the asset over at once drops the offer of the previous owner
+		{name: "transfer at once", client: "Alice", args: []string{"TransferAssetOwnership", "asset1", "Carol", "Org1MSP"}, wantOwner: "Carol"},
+		{name: "accept offer of previous owner", client: "Bob", args: []string{"AcceptTransfer", "asset1"}, wantMessage: "has no pending transfer"},
+		{name: "propose by new owner", client: "Carol", args: []string{"ProposeTransfer", "asset1", "Bob", "Org1MSP", "1h"}},
+		{name: "accept after expiry", client: "Bob", args: []string{"AcceptTransfer", "asset1"}, advance: 2 * time.Hour, wantMessage: "expired at 2024-01-01T13:00:00Z"},
+		{name: "propose after expiry", client: "Carol", args: []string{"ProposeTransfer", "asset1", "Bob", "Org1MSP", "1h"}},
+		{name: "accept", client: "Bob", args: []string{"AcceptTransfer", "asset1"}, wantOwner: "Bob",
+			wantEvents: assetevents.NameAssetTransferred + "," + assetevents.NameTransferAccepted},
+		{name: "accept twice", client: "Bob", args: []string{"AcceptTransfer", "asset1"}, wantMessage: "has no pending transfer"},
+		// deleting the asset drops its offer too
+		{name: "propose before delete", client: "Bob", args: []string{"ProposeTransfer", "asset1", "Alice", "Org1MSP", "1h"}},
+		{name: "delete", client: "Bob", args: []string{"DeleteAsset", "asset1"}},
+		{name: "pending after delete", client: "Alice", args: []string{"QueryPendingTransfer", "asset1"}, wantMessage: "has no pending transfer"},
+	}
+	for i, tt := range tests {
+		now = now.Add(tt.advance)
+		if err := stub.SetCreator(clients[tt.client]); err != nil {
+			t.Fatalf("failed to set creator: %v", err)
+		}
+		status, message, _ := invoke(stub, fmt.Sprintf("tx%d", i), tt.args...)
+		var events []string
+		select {
+		case e := <-stub.ChaincodeEventsChannel:
+			events = unpackEventNames(t, e.EventName, e.Payload)
+		default:
+		}
+		if tt.wantMessage != "" {
+			if status == shim.OK || !strings.Contains(message, tt.wantMessage) {
+				t.Errorf("%s: got %d (%s), want an error containing %q", tt.name, status, message, tt.wantMessage)
+			}
+			continue
+		}
+		if status != shim.OK {
+			t.Errorf("%s: got %d (%s), want OK", tt.name, status, message)
+		}
+		if got := strings.Join(events, ","); tt.wantEvents != "" && got != tt.wantEvents {
+			t.Errorf("%s: events = %s, want %s", tt.name, got, tt.wantEvents)
+		}
+		if tt.wantOwner != "" {
+			_, _, payload := invoke(stub, fmt.Sprintf("query%d", i), "QueryAsset", "asset1")
+			if owned := unmarshalAsset(t, payload); owned.Owner != tt.wantOwner || owned.OwnerID != clients[tt.wantOwner].ID {
+				t.Errorf("%s: asset = %+v, want owned by %s", tt.name, owned, tt.wantOwner)
+			}
+		}
+	}
+}
+
+// unpackEventNames returns the names of the asset events of a chaincode event, alone or in an AssetEvents envelope
+func unpackEventNames(t *testing.T, name string, payload []byte) []string {
+	t.Helper()
+	events, err := assetevents.Unpack(name, payload)
+	if err != nil {
+		t.Fatalf("failed to unpack %s event %s: %v", name, payload, err)
+	}
+	names := make([]string, 0, len(events))
+	for _, event := range events {
+		names = append(names, event.EventHeader().Name)
+	}
+	return names
+}
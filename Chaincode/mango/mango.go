@@ -0,0 +1,533 @@
+// Package mango evaluates CouchDB Mango queries in memory.
+//
+// On a peer that uses CouchDB as state database, GetQueryResult sends the query
+// to CouchDB, which is not there when a chaincode is tested offline. This package
+// runs the same queries over json documents kept in memory (see chaincodetest.Stub),
+// so that the rich queries of a chaincode can be tested without CouchDB.
+//
+// A query looks like this:
+//
+//	{
+//	  "selector": {"docType": "asset", "owner": "Alice", "price": {"$gt": 100}},
+//	  "sort": [{"price": "desc"}],
+//	  "limit": 10,
+//	  "skip": 0,
+//	  "fields": ["ID", "price"]
+//	}
+//
+// Supported selector operators are $eq, $ne, $gt, $gte, $lt, $lte, $exists, $type,
+// $in, $nin, $size, $mod, $regex, $all, $elemMatch, $and, $or, $nor and $not.
+// Fields can be nested ({"a": {"b": 1}}) or dotted ("a.b").
+// Like in CouchDB, a missing field only matches {"$exists": false}.
+package mango
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Query is a parsed Mango query
+type Query struct {
+	Selector map[string]interface{} `json:"selector"`
+	Sort     []interface{}          `json:"sort,omitempty"`
+	Limit    int                    `json:"limit,omitempty"`
+	Skip     int                    `json:"skip,omitempty"`
+	Fields   []string               `json:"fields,omitempty"`
+
+	// UseIndex only tells CouchDB which index to use, it does not change the result
+	UseIndex interface{} `json:"use_index,omitempty"`
+}
+
+// Document is a json document of the state database
+type Document struct {
+	Key   string
+	Value []byte
+}
+
+// ParseQuery parses a Mango query. The selector is checked right away,
+// so that an unknown operator is reported even if there is no document to match.
+func ParseQuery(query string) (*Query, error) {
+	dec := json.NewDecoder(strings.NewReader(query))
+	dec.DisallowUnknownFields()
+
+	q := new(Query)
+	err := dec.Decode(q)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query: %v", err)
+	}
+	if q.Selector == nil {
+		return nil, fmt.Errorf("invalid query: selector is required")
+	}
+	if q.Limit < 0 || q.Skip < 0 {
+		return nil, fmt.Errorf("invalid query: limit and skip must not be negative")
+	}
+
+	_, err = Match(q.Selector, map[string]interface{}{})
+	if err != nil {
+		return nil, fmt.Errorf("invalid query: %v", err)
+	}
+	_, err = q.sortFields()
+	if err != nil {
+		return nil, fmt.Errorf("invalid query: %v", err)
+	}
+	return q, nil
+}
+
+// Run returns the documents matching the query, sorted, skipped, limited and
+// projected the way CouchDB would. Documents that are not json objects never match.
+// Documents that sort the same are kept in key order.
+func (q *Query) Run(docs []Document) ([]Document, error) {
+	type match struct {
+		doc   Document
+		value map[string]interface{}
+	}
+
+	var matches []match
+	for _, doc := range docs {
+		var value map[string]interface{}
+		if json.Unmarshal(doc.Value, &value) != nil {
+			continue
+		}
+		ok, err := Match(q.Selector, value)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matches = append(matches, match{doc: doc, value: value})
+		}
+	}
+
+	sortFields, err := q.sortFields()
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		for _, field := range sortFields {
+			a, _ := lookup(matches[i].value, field.path)
+			b, _ := lookup(matches[j].value, field.path)
+			c := compare(a, b)
+			if c != 0 {
+				return (c < 0) != field.descending
+			}
+		}
+		return matches[i].doc.Key < matches[j].doc.Key
+	})
+
+	if q.Skip >= len(matches) {
+		return []Document{}, nil
+	}
+	matches = matches[q.Skip:]
+	if q.Limit > 0 && q.Limit < len(matches) {
+		matches = matches[:q.Limit]
+	}
+
+	results := make([]Document, 0, len(matches))
+	for _, m := range matches {
+		doc := m.doc
+		if len(q.Fields) > 0 {
+			doc.Value, err = project(m.value, q.Fields)
+			if err != nil {
+				return nil, err
+			}
+		}
+		results = append(results, doc)
+	}
+	return results, nil
+}
+
+type sortField struct {
+	path       string
+	descending bool
+}
+
+// sortFields reads Sort, which holds field names ("owner") or single field objects ({"price": "desc"})
+func (q *Query) sortFields() ([]sortField, error) {
+	fields := make([]sortField, 0, len(q.Sort))
+	for _, s := range q.Sort {
+		switch v := s.(type) {
+		case string:
+			fields = append(fields, sortField{path: v})
+		case map[string]interface{}:
+			if len(v) != 1 {
+				return nil, fmt.Errorf("sort entry %v must have exactly one field", v)
+			}
+			for path, direction := range v {
+				switch direction {
+				case "asc":
+					fields = append(fields, sortField{path: path})
+				case "desc":
+					fields = append(fields, sortField{path: path, descending: true})
+				default:
+					return nil, fmt.Errorf("sort direction of %s must be asc or desc, got %v", path, direction)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("invalid sort entry %v", s)
+		}
+	}
+	return fields, nil
+}
+
+// project keeps only the given fields of doc
+func project(doc map[string]interface{}, fields []string) ([]byte, error) {
+	projected := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		if strings.Contains(field, ".") {
+			return nil, fmt.Errorf("nested field %s is not supported in fields", field)
+		}
+		if value, ok := doc[field]; ok {
+			projected[field] = value
+		}
+	}
+	return json.Marshal(projected)
+}
+
+// Match tells whether the json document doc (as decoded by encoding/json) matches selector.
+// Every condition is evaluated, even once one failed, so that an invalid operator
+// is always reported.
+func Match(selector map[string]interface{}, doc interface{}) (bool, error) {
+	matched := true
+	for key, condition := range selector {
+		var ok bool
+		var err error
+		switch key {
+		case "$and", "$or", "$nor":
+			ok, err = matchCombination(key, condition, doc)
+		case "$not":
+			sub, isSelector := condition.(map[string]interface{})
+			if !isSelector {
+				return false, fmt.Errorf("$not needs a selector, got %v", condition)
+			}
+			ok, err = Match(sub, doc)
+			ok = !ok
+		default:
+			if strings.HasPrefix(key, "$") {
+				return false, fmt.Errorf("unknown operator %s", key)
+			}
+			value, found := lookup(doc, key)
+			ok, err = matchCondition(condition, value, found)
+		}
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			matched = false
+		}
+	}
+	return matched, nil
+}
+
+// matchCombination evaluates $and, $or and $nor, which hold an array of selectors
+func matchCombination(operator string, condition interface{}, doc interface{}) (bool, error) {
+	selectors, ok := condition.([]interface{})
+	if !ok {
+		return false, fmt.Errorf("%s needs an array of selectors, got %v", operator, condition)
+	}
+
+	matched := 0
+	for _, s := range selectors {
+		sub, ok := s.(map[string]interface{})
+		if !ok {
+			return false, fmt.Errorf("%s needs an array of selectors, got %v", operator, s)
+		}
+		ok, err := Match(sub, doc)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			matched++
+		}
+	}
+
+	switch operator {
+	case "$and":
+		return matched == len(selectors), nil
+	case "$or":
+		return matched > 0, nil
+	default: // $nor
+		return matched == 0, nil
+	}
+}
+
+// matchCondition matches the value of a field against its condition, which is either
+// a value (implicit $eq), an object of operators, or an object of sub fields
+func matchCondition(condition interface{}, value interface{}, found bool) (bool, error) {
+	operators, isObject := condition.(map[string]interface{})
+	if !isObject || len(operators) == 0 {
+		return found && compare(value, condition) == 0, nil
+	}
+
+	if !hasOperators(operators) {
+		// {"a": {"b": 1}} is the same as {"a.b": 1}
+		return Match(operators, value)
+	}
+
+	matched := true
+	for operator, arg := range operators {
+		ok, err := matchOperator(operator, arg, value, found)
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			matched = false
+		}
+	}
+	return matched, nil
+}
+
+func hasOperators(m map[string]interface{}) bool {
+	for key := range m {
+		if strings.HasPrefix(key, "$") {
+			return true
+		}
+	}
+	return false
+}
+
+func matchOperator(operator string, arg interface{}, value interface{}, found bool) (bool, error) {
+	if operator == "$exists" {
+		exists, ok := arg.(bool)
+		if !ok {
+			return false, fmt.Errorf("$exists needs a boolean, got %v", arg)
+		}
+		return found == exists, nil
+	}
+
+	switch operator {
+	case "$eq", "$ne", "$gt", "$gte", "$lt", "$lte", "$type", "$in", "$nin", "$size", "$mod", "$regex", "$all", "$elemMatch", "$not":
+	default:
+		return false, fmt.Errorf("unknown operator %s", operator)
+	}
+	if !found {
+		// only $exists matches a missing field, but the argument is still checked
+		_, err := matchOperator(operator, arg, nil, true)
+		return false, err
+	}
+
+	switch operator {
+	case "$eq":
+		return compare(value, arg) == 0, nil
+	case "$ne":
+		return compare(value, arg) != 0, nil
+	case "$gt":
+		return sameKind(value, arg) && compare(value, arg) > 0, nil
+	case "$gte":
+		return sameKind(value, arg) && compare(value, arg) >= 0, nil
+	case "$lt":
+		return sameKind(value, arg) && compare(value, arg) < 0, nil
+	case "$lte":
+		return sameKind(value, arg) && compare(value, arg) <= 0, nil
+	case "$type":
+		name, ok := arg.(string)
+		if !ok {
+			return false, fmt.Errorf("$type needs a string, got %v", arg)
+		}
+		return typeName(value) == name, nil
+	case "$in", "$nin":
+		list, ok := arg.([]interface{})
+		if !ok {
+			return false, fmt.Errorf("%s needs an array, got %v", operator, arg)
+		}
+		in := false
+		for _, element := range list {
+			if compare(value, element) == 0 {
+				in = true
+				break
+			}
+		}
+		return in == (operator == "$in"), nil
+	case "$size":
+		size, ok := arg.(float64)
+		if !ok {
+			return false, fmt.Errorf("$size needs a number, got %v", arg)
+		}
+		array, isArray := value.([]interface{})
+		return isArray && float64(len(array)) == size, nil
+	case "$mod":
+		args, ok := arg.([]interface{})
+		if !ok || len(args) != 2 {
+			return false, fmt.Errorf("$mod needs [divisor, remainder], got %v", arg)
+		}
+		divisor, ok1 := args[0].(float64)
+		remainder, ok2 := args[1].(float64)
+		if !ok1 || !ok2 || divisor == 0 {
+			return false, fmt.Errorf("$mod needs [divisor, remainder], got %v", arg)
+		}
+		n, isNumber := value.(float64)
+		return isNumber && n == math.Trunc(n) && math.Mod(n, divisor) == remainder, nil
+	case "$regex":
+		pattern, ok := arg.(string)
+		if !ok {
+			return false, fmt.Errorf("$regex needs a string, got %v", arg)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return false, fmt.Errorf("invalid $regex %s: %v", pattern, err)
+		}
+		s, isString := value.(string)
+		return isString && re.MatchString(s), nil
+	case "$all":
+		list, ok := arg.([]interface{})
+		if !ok {
+			return false, fmt.Errorf("$all needs an array, got %v", arg)
+		}
+		array, isArray := value.([]interface{})
+		if !isArray {
+			return false, nil
+		}
+		for _, wanted := range list {
+			in := false
+			for _, element := range array {
+				if compare(element, wanted) == 0 {
+					in = true
+					break
+				}
+			}
+			if !in {
+				return false, nil
+			}
+		}
+		return true, nil
+	case "$elemMatch":
+		sub, ok := arg.(map[string]interface{})
+		if !ok {
+			return false, fmt.Errorf("$elemMatch needs a selector, got %v", arg)
+		}
+		array, isArray := value.([]interface{})
+		if !isArray {
+			_, err := matchCondition(sub, nil, true)
+			return false, err
+		}
+		for _, element := range array {
+			ok, err := matchCondition(sub, element, true)
+			if err != nil || ok {
+				return ok, err
+			}
+		}
+		return false, nil
+	default: // $not on a field
+		ok, err := matchCondition(arg, value, true)
+		return !ok, err
+	}
+}
+
+// lookup returns the value of the dotted path in doc
+func lookup(doc interface{}, path string) (interface{}, bool) {
+	value := doc
+	for _, name := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		value, ok = object[name]
+		if !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// typeName is the name CouchDB gives to the json type of v
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// typeRank orders the json types the way CouchDB collates them:
+// null < booleans < numbers < strings < arrays < objects
+func typeRank(v interface{}) int {
+	switch v.(type) {
+	case nil:
+		return 0
+	case bool:
+		return 1
+	case float64:
+		return 2
+	case string:
+		return 3
+	case []interface{}:
+		return 4
+	default:
+		return 5
+	}
+}
+
+// sameKind tells whether a and b have the same json type, range operators
+// ($gt, $lt...) never match a value of another type
+func sameKind(a, b interface{}) bool {
+	return typeRank(a) == typeRank(b)
+}
+
+// compare returns -1, 0 or 1 as a is before, equal to or after b in CouchDB collation
+func compare(a, b interface{}) int {
+	ra, rb := typeRank(a), typeRank(b)
+	if ra != rb {
+		return sign(ra - rb)
+	}
+
+	switch va := a.(type) {
+	case nil:
+		return 0
+	case bool:
+		vb := b.(bool)
+		if va == vb {
+			return 0
+		}
+		if !va {
+			return -1
+		}
+		return 1
+	case float64:
+		vb := b.(float64)
+		if va < vb {
+			return -1
+		}
+		if va > vb {
+			return 1
+		}
+		return 0
+	case string:
+		return strings.Compare(va, b.(string))
+	case []interface{}:
+		vb := b.([]interface{})
+		for i := 0; i < len(va) && i < len(vb); i++ {
+			c := compare(va[i], vb[i])
+			if c != 0 {
+				return c
+			}
+		}
+		return sign(len(va) - len(vb))
+	default:
+		// objects are only compared for equality
+		ja, _ := json.Marshal(a)
+		jb, _ := json.Marshal(b)
+		return bytes.Compare(ja, jb)
+	}
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
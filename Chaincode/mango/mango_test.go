@@ -0,0 +1,110 @@
+package mango
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+var docs = []Document{
+	{Key: "asset1", Value: []byte(`{"docType":"asset","ID":"asset1","owner":"Alice","color":"red","price":100,"tags":["new","sale"],"location":{"city":"Pune"}}`)},
+	{Key: "asset2", Value: []byte(`{"docType":"asset","ID":"asset2","owner":"Bob","color":"blue","price":200,"tags":["sale"]}`)},
+	{Key: "asset3", Value: []byte(`{"docType":"asset","ID":"asset3","owner":"Alice","color":"green","price":300,"location":{"city":"Mumbai"}}`)},
+	{Key: "doc1", Value: []byte(`{"docType":"document","name":"report","price":"free"}`)},
+	{Key: "\x00owner~id\x00Alice\x00asset1\x00", Value: []byte{0x00}},
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    string
+		wantErr string
+	}{
+		{name: "implicit equality", query: `{"selector":{"owner":"Alice"}}`, want: "asset1,asset3"},
+		{name: "several fields", query: `{"selector":{"owner":"Alice","color":"green"}}`, want: "asset3"},
+		{name: "range", query: `{"selector":{"price":{"$gt":100,"$lte":300}}}`, want: "asset2,asset3"},
+		{name: "range ignores other types", query: `{"selector":{"price":{"$gte":0}}}`, want: "asset1,asset2,asset3"},
+		{name: "ne skips missing fields", query: `{"selector":{"location.city":{"$ne":"Pune"}}}`, want: "asset3"},
+		{name: "exists false", query: `{"selector":{"docType":"asset","location":{"$exists":false}}}`, want: "asset2"},
+		{name: "in", query: `{"selector":{"color":{"$in":["red","blue"]}}}`, want: "asset1,asset2"},
+		{name: "nin", query: `{"selector":{"docType":"asset","color":{"$nin":["red","blue"]}}}`, want: "asset3"},
+		{name: "nested", query: `{"selector":{"location":{"city":"Pune"}}}`, want: "asset1"},
+		{name: "or", query: `{"selector":{"$or":[{"owner":"Bob"},{"color":"green"}]}}`, want: "asset2,asset3"},
+		{name: "not", query: `{"selector":{"docType":"asset","$not":{"owner":"Alice"}}}`, want: "asset2"},
+		{name: "nor", query: `{"selector":{"docType":"asset","$nor":[{"owner":"Bob"},{"color":"red"}]}}`, want: "asset3"},
+		{name: "regex", query: `{"selector":{"owner":{"$regex":"^A"}}}`, want: "asset1,asset3"},
+		{name: "all", query: `{"selector":{"tags":{"$all":["sale","new"]}}}`, want: "asset1"},
+		{name: "elemMatch", query: `{"selector":{"tags":{"$elemMatch":{"$eq":"sale"}}}}`, want: "asset1,asset2"},
+		{name: "size", query: `{"selector":{"tags":{"$size":1}}}`, want: "asset2"},
+		{name: "type", query: `{"selector":{"price":{"$type":"string"}}}`, want: "doc1"},
+		{name: "mod", query: `{"selector":{"price":{"$mod":[200,100]}}}`, want: "asset1,asset3"},
+		{name: "sort desc", query: `{"selector":{"docType":"asset"},"sort":[{"price":"desc"}]}`, want: "asset3,asset2,asset1"},
+		{name: "sort then key", query: `{"selector":{"docType":"asset"},"sort":["owner"]}`, want: "asset1,asset3,asset2"},
+		{name: "skip and limit", query: `{"selector":{"docType":"asset"},"skip":1,"limit":1}`, want: "asset2"},
+		{name: "skip everything", query: `{"selector":{"docType":"asset"},"skip":5}`, want: ""},
+		{name: "unknown operator", query: `{"selector":{"price":{"$greater":1}}}`, wantErr: "unknown operator $greater"},
+		{name: "unknown top level operator", query: `{"selector":{"owner":"Nobody","$xor":[]}}`, wantErr: "unknown operator $xor"},
+		{name: "invalid regex", query: `{"selector":{"owner":{"$regex":"("}}}`, wantErr: "invalid $regex"},
+		{name: "missing selector", query: `{"sort":["owner"]}`, wantErr: "selector is required"},
+		{name: "invalid sort", query: `{"selector":{},"sort":[{"price":"up"}]}`, wantErr: "must be asc or desc"},
+		{name: "unknown query field", query: `{"selector":{},"limits":1}`, wantErr: `unknown field "limits"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := run(tt.query)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("err = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("query failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Run = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// run runs query over docs and returns the keys of the results, joined by commas
+func run(query string) (string, error) {
+	q, err := ParseQuery(query)
+	if err != nil {
+		return "", err
+	}
+	results, err := q.Run(docs)
+	if err != nil {
+		return "", err
+	}
+	keys := make([]string, 0, len(results))
+	for _, doc := range results {
+		keys = append(keys, doc.Key)
+	}
+	return strings.Join(keys, ","), nil
+}
+
+func TestRunFields(t *testing.T) {
+	q, err := ParseQuery(`{"selector":{"ID":"asset2"},"fields":["ID","price"]}`)
+	if err != nil {
+		t.Fatalf("ParseQuery failed: %v", err)
+	}
+	results, err := q.Run(docs)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(results[0].Value, &doc); err != nil {
+		t.Fatalf("failed to unmarshal %s: %v", results[0].Value, err)
+	}
+	if len(doc) != 2 || doc["ID"] != "asset2" || doc["price"] != 200.0 {
+		t.Errorf("projected document = %s, want ID and price of asset2", results[0].Value)
+	}
+}
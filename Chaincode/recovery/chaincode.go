@@ -0,0 +1,59 @@
+package recovery
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"runtime/debug"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// Logger receives a line for every recovered panic, with the transaction ID,
+// the function and the stack trace. It writes to the chaincode container log by default.
+var Logger = log.New(os.Stderr, "", log.LstdFlags)
+
+// Chaincode is a shim.Chaincode whose Init and Invoke never panic:
+// a panic of the wrapped chaincode is logged and returned to the client as a shim.Error.
+type Chaincode struct {
+	shim.Chaincode
+}
+
+// Wrap adds panic recovery to cc.
+// cc can be a low level chaincode as well as the chaincode built by contractapi.NewChaincode,
+// in which case a panic in a contract function (or in its before / after transaction
+// functions) becomes the error of the transaction:
+//
+//	chaincode, err := contractapi.NewChaincode(new(highlevel.SimpleAssetChaincode))
+//	...
+//	err = shim.Start(recovery.Wrap(chaincode))
+func Wrap(cc shim.Chaincode) *Chaincode {
+	return &Chaincode{Chaincode: cc}
+}
+
+// Init calls Init of the wrapped chaincode and recovers its panics
+func (c *Chaincode) Init(stub shim.ChaincodeStubInterface) (response pb.Response) {
+	defer func() {
+		if r := recover(); r != nil {
+			response = panicResponse(stub, r)
+		}
+	}()
+
+	return c.Chaincode.Init(stub)
+}
+
+// Invoke calls Invoke of the wrapped chaincode and recovers its panics
+func (c *Chaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
+	return Invoke(c.Chaincode, stub)
+}
+
+// panicResponse logs the recovered value r and turns it into the error response of the transaction.
+// The stack trace only goes to the log, the client gets the transaction ID to look it up.
+func panicResponse(stub shim.ChaincodeStubInterface, r interface{}) pb.Response {
+	txID := stub.GetTxID()
+	function, _ := stub.GetFunctionAndParameters()
+
+	Logger.Printf("Chaincode panicked in function %q of transaction %s: %v\n%s", function, txID, r, debug.Stack())
+	return shim.Error(fmt.Sprintf("Chaincode panicked in function %q of transaction %s: %v", function, txID, r))
+}
@@ -0,0 +1,137 @@
+package recovery
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// panickyChaincode panics on "Crash" and succeeds otherwise
+type panickyChaincode struct{}
+
+func (cc *panickyChaincode) Init(stub shim.ChaincodeStubInterface) pb.Response {
+	panic("init failed")
+}
+
+func (cc *panickyChaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
+	function, _ := stub.GetFunctionAndParameters()
+	if function == "Crash" {
+		var m map[string]int
+		m["boom"]++ // assignment to entry in nil map
+	}
+	return shim.Success([]byte(function))
+}
+
+// panickyContract is the contractapi flavour of panickyChaincode
+type panickyContract struct {
+	contractapi.Contract
+}
+
+func (c *panickyContract) Crash(ctx contractapi.TransactionContextInterface, assetID string) error {
+	var ids []string
+	_ = ids[len(assetID)] // index out of range
+	return nil
+}
+
+func (c *panickyContract) Ping(ctx contractapi.TransactionContextInterface) string {
+	return "pong"
+}
+
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	previous := Logger
+	Logger = log.New(&buf, "", 0)
+	t.Cleanup(func() { Logger = previous })
+	return &buf
+}
+
+func TestWrap(t *testing.T) {
+	contractChaincode, err := contractapi.NewChaincode(new(panickyContract))
+	if err != nil {
+		t.Fatalf("failed to create contract chaincode: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		cc          shim.Chaincode
+		args        []string
+		wantStatus  int32
+		wantMessage string
+		wantLog     string
+	}{
+		{
+			name:       "shim no panic",
+			cc:         new(panickyChaincode),
+			args:       []string{"Ping"},
+			wantStatus: shim.OK,
+		},
+		{
+			name:        "shim panic",
+			cc:          new(panickyChaincode),
+			args:        []string{"Crash"},
+			wantStatus:  shim.ERROR,
+			wantMessage: `Chaincode panicked in function "Crash" of transaction tx1: assignment to entry in nil map`,
+			wantLog:     "chaincode_test.go",
+		},
+		{
+			name:       "contractapi no panic",
+			cc:         contractChaincode,
+			args:       []string{"Ping"},
+			wantStatus: shim.OK,
+		},
+		{
+			name:        "contractapi panic",
+			cc:          contractChaincode,
+			args:        []string{"Crash", "asset1"},
+			wantStatus:  shim.ERROR,
+			wantMessage: `Chaincode panicked in function "Crash" of transaction tx1: runtime error: index out of range`,
+			wantLog:     "(*panickyContract).Crash",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := captureLog(t)
+
+			args := make([][]byte, 0, len(tt.args))
+			for _, arg := range tt.args {
+				args = append(args, []byte(arg))
+			}
+			stub := shimtest.NewMockStub("recovery", Wrap(tt.cc))
+			response := stub.MockInvoke("tx1", args)
+
+			if response.Status != tt.wantStatus {
+				t.Fatalf("status = %d (%s), want %d", response.Status, response.Message, tt.wantStatus)
+			}
+			if !strings.Contains(response.Message, tt.wantMessage) {
+				t.Errorf("message = %q, want it to contain %q", response.Message, tt.wantMessage)
+			}
+			if tt.wantLog == "" {
+				if logs.Len() != 0 {
+					t.Errorf("unexpected log %s", logs)
+				}
+				return
+			}
+			if !strings.Contains(logs.String(), "tx1") || !strings.Contains(logs.String(), tt.wantLog) {
+				t.Errorf("log does not contain the transaction ID and %q:\n%s", tt.wantLog, logs)
+			}
+		})
+	}
+}
+
+func TestWrapInit(t *testing.T) {
+	captureLog(t)
+
+	stub := shimtest.NewMockStub("recovery", Wrap(new(panickyChaincode)))
+	response := stub.MockInit("tx0", nil)
+	if response.Status != shim.ERROR || !strings.Contains(response.Message, "init failed") {
+		t.Errorf("response = %d (%s), want an error containing %q", response.Status, response.Message, "init failed")
+	}
+}
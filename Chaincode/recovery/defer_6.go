@@ -5,24 +5,48 @@
 // resources are released or cleanup actions are performed regardless of whether
 // the function exits normally or panics.
 
+// Package recovery shows how defer and recover keep a chaincode entry point
+// from crashing the chaincode container when it panics.
+package recovery
+
+import (
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/asset"
+)
+
 // Here's an example of how defer can be used in a chaincode function:
 // Using defer in normal functions
-func (s *SimpleAssetChaincode) CreateAsset(ctx contractapi.TransactionContextInterface, asset Asset) error {
-    // Open a database connection
-    db, err := OpenDatabase()
-    if err != nil {
-        return err
-    }
-    defer db.Close() // Schedule the database connection to be closed when the function returns
-
-    // Perform database operations
-    err = db.Insert(asset)
-    if err != nil {
-        return err
-    }
-
-    // If no errors occurred, return nil
-    return nil
+
+// Database is any resource that has to be released once we are done with it
+type Database interface {
+	Insert(a *asset.Asset) error
+	Close() error
+}
+
+// DatabaseExample stores assets in a Database opened by OpenDatabase
+type DatabaseExample struct {
+	OpenDatabase func() (Database, error)
+}
+
+// CreateAsset inserts the asset into a freshly opened database
+func (e *DatabaseExample) CreateAsset(a *asset.Asset) error {
+	// Open a database connection
+	db, err := e.OpenDatabase()
+	if err != nil {
+		return err
+	}
+	defer db.Close() // Schedule the database connection to be closed when the function returns
+
+	// Perform database operations
+	err = db.Insert(a)
+	if err != nil {
+		return err
+	}
+
+	// If no errors occurred, return nil
+	return nil
 }
 
 
@@ -34,31 +58,21 @@ func (s *SimpleAssetChaincode) CreateAsset(ctx contractapi.TransactionContextInt
 // restarted, logs cannot be found, and the problem cannot be located immediately.
 // To prevent this case, add the defer statement at the entry point of the Invoke
 // function. When a panic occurs, the error is returned to the client.
-func (s *SimpleAssetChaincode) Invoke(stub shim.ChaincodeStubInterface) (string, error) {
-    
-
-     defer func() {
-        if err := recover(); err != nil {
-            // Handle the panic by returning an error response  
-            errMsg := fmt.Sprintf("Chaincode panicked: %v", err)
-            logger.Errorf(errMsg)
-            panic(errMsg)
-        }
-    }()
-    
-    // Actual Invoke logic goes here...
-    function, args := ctx.GetStub().GetFunctionAndParameters()
-
-    // Route to the appropriate handler function
-    if function == "CreateAsset" {
-        return s.CreateAsset(ctx, args)
-    } else if function == "UpdateAsset" {
-        return s.UpdateAsset(ctx, args)
-    } else if function == "QueryAsset" {
-        return s.QueryAsset(ctx, args)
-    }
-
-    return nil, fmt.Errorf("Invalid Smart Contract function name.")
+
+// Invoke is the entry point of cc wrapped with the deferred recovery logic.
+// The actual Invoke logic (routing to CreateAsset, UpdateAsset, QueryAsset...) is the one of cc.
+func Invoke(cc shim.Chaincode, stub shim.ChaincodeStubInterface) (response pb.Response) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			// Handle the panic by logging it (with the stack, so the problem can be located)
+			// and turning it into an error response for the client
+			response = panicResponse(stub, r)
+		}
+	}()
+
+	// Actual Invoke logic goes here...
+	return cc.Invoke(stub)
 }
 
 
@@ -70,8 +84,9 @@ func (s *SimpleAssetChaincode) Invoke(stub shim.ChaincodeStubInterface) (string,
 // 2. Inside the deferred function, recover() is called to capture any panic
 // that occurs within the surrounding function.
 // 3. If a panic is recovered (i.e., if recover() returns a non-nil value),
-// the deferred function handles the panic by logging an error message,
-// constructing an error response, and then panicking again with the error response encoded as JSON.
+// the deferred function logs the transaction ID, the function name and the stack trace,
+// and replaces the response of Invoke with a shim.Error.
+// This works because the response is a named result: a deferred function can still change it.
 
 
 // Invoke Function Flow:
@@ -81,8 +96,13 @@ func (s *SimpleAssetChaincode) Invoke(stub shim.ChaincodeStubInterface) (string,
 // 3. Based on the extracted function name (function), it routes the invocation to the corresponding function (invoke, delete, or query).
 // 4. If the function name is "delete", the control will pass to the delete function.
 // 5. However, if a panic occurs during the execution of the delete function (or any other function invoked within Invoke), the deferred error recovery logic will be triggered.
-// 6. In case of a panic, the deferred function logs an error, constructs an error response with status code 500 and an error message, marshals it into JSON, and then panics again with the JSON-encoded error response.
-// 7. This final panic will bubble up and terminate the chaincode execution, but it ensures that the panic is handled gracefully, preventing the chaincode container from crashing and providing an informative error response to the caller.
+// 6. In case of a panic, the deferred function logs the panic with its stack trace and returns a shim.Error to the client.
+// 7. The panic does not go any further, so the chaincode container keeps running and
+// the client gets an informative error response instead of a dropped connection.
+// Do NOT panic again inside the deferred function: the new panic would crash the container anyway.
+//
+// Wrap (see chaincode.go) does the same for both Init and Invoke of any shim.Chaincode,
+// including the chaincode built by contractapi.NewChaincode.
 
 
 // Panic() function
@@ -0,0 +1,191 @@
+// Package router replaces the hand written if/else chain in the Invoke function
+// of a low level (shim) chaincode.
+//
+// Every chaincode function is registered under its name together with a handler.
+// A handler takes the stub followed by its own typed parameters and returns a
+// pb.Response, for example
+//
+//	func (s *SimpleAssetChaincode) TransferAssetOwnership(stub shim.ChaincodeStubInterface, assetID string, newOwner string) pb.Response
+//
+// The router checks the number of arguments, decodes each argument into the
+// type of its parameter (strings are passed as they are, everything else is
+// decoded from json) and calls the handler.
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// Error codes of the structured errors returned by the router
+const (
+	CodeUnknownFunction    = "UNKNOWN_FUNCTION"
+	CodeWrongArgumentCount = "WRONG_ARGUMENT_COUNT"
+	CodeInvalidArgument    = "INVALID_ARGUMENT"
+)
+
+// Error is the structured error returned by the router, it is sent to the
+// client as the json message of the shim.Error response
+type Error struct {
+	Code      string   `json:"code"`
+	Message   string   `json:"message"`
+	Function  string   `json:"function"`
+	Argument  int      `json:"argument,omitempty"`
+	Available []string `json:"available,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Response turns the error into a shim.Error with the json encoded error as message
+func (e *Error) Response() pb.Response {
+	errJSON, err := json.Marshal(e)
+	if err != nil {
+		return shim.Error(e.Message)
+	}
+	return shim.Error(string(errJSON))
+}
+
+// ParseError reads back the Error from the message of a shim.Error response.
+// It returns false if the message is not a router error.
+func ParseError(message string) (*Error, bool) {
+	e := new(Error)
+	err := json.Unmarshal([]byte(message), e)
+	if err != nil || e.Code == "" {
+		return nil, false
+	}
+	return e, true
+}
+
+var (
+	stubType     = reflect.TypeOf((*shim.ChaincodeStubInterface)(nil)).Elem()
+	responseType = reflect.TypeOf(pb.Response{})
+	bytesType    = reflect.TypeOf([]byte(nil))
+)
+
+// handler is a registered function with the types of its parameters
+type handler struct {
+	fn     reflect.Value
+	params []reflect.Type
+}
+
+// Router routes a chaincode invocation to the handler registered for the function
+type Router struct {
+	handlers map[string]*handler
+
+	// Decode decodes a non string argument into v.
+	// It defaults to json.Unmarshal.
+	Decode func(data []byte, v interface{}) error
+}
+
+// New creates an empty Router
+func New() *Router {
+	return &Router{
+		handlers: make(map[string]*handler),
+		Decode:   json.Unmarshal,
+	}
+}
+
+// Register registers fn as the handler of the chaincode function name.
+// fn must be a func(shim.ChaincodeStubInterface, ...) pb.Response.
+func (r *Router) Register(name string, fn interface{}) error {
+	if _, ok := r.handlers[name]; ok {
+		return fmt.Errorf("function %s is already registered", name)
+	}
+
+	fnValue := reflect.ValueOf(fn)
+	fnType := fnValue.Type()
+	if fnType.Kind() != reflect.Func {
+		return fmt.Errorf("handler of %s is a %s, not a func", name, fnType)
+	}
+	if fnType.IsVariadic() {
+		return fmt.Errorf("handler of %s must not be variadic", name)
+	}
+	if fnType.NumIn() == 0 || fnType.In(0) != stubType {
+		return fmt.Errorf("first parameter of the handler of %s must be a shim.ChaincodeStubInterface", name)
+	}
+	if fnType.NumOut() != 1 || fnType.Out(0) != responseType {
+		return fmt.Errorf("handler of %s must return a pb.Response", name)
+	}
+
+	params := make([]reflect.Type, 0, fnType.NumIn()-1)
+	for i := 1; i < fnType.NumIn(); i++ {
+		params = append(params, fnType.In(i))
+	}
+
+	r.handlers[name] = &handler{fn: fnValue, params: params}
+	return nil
+}
+
+// Functions returns the names of the registered functions in alphabetical order
+func (r *Router) Functions() []string {
+	names := make([]string, 0, len(r.handlers))
+	for name := range r.handlers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Handle routes the invocation of stub to the handler of its function
+func (r *Router) Handle(stub shim.ChaincodeStubInterface) pb.Response {
+	function, args := stub.GetFunctionAndParameters()
+
+	h, ok := r.handlers[function]
+	if !ok {
+		return (&Error{
+			Code:      CodeUnknownFunction,
+			Message:   fmt.Sprintf("function %s does not exist", function),
+			Function:  function,
+			Available: r.Functions(),
+		}).Response()
+	}
+
+	if len(args) != len(h.params) {
+		return (&Error{
+			Code:     CodeWrongArgumentCount,
+			Message:  fmt.Sprintf("incorrect number of arguments for %s, expecting %d but got %d", function, len(h.params), len(args)),
+			Function: function,
+		}).Response()
+	}
+
+	in := make([]reflect.Value, 0, len(args)+1)
+	in = append(in, reflect.ValueOf(stub))
+	for i, arg := range args {
+		value, err := r.decodeArg(arg, h.params[i])
+		if err != nil {
+			return (&Error{
+				Code:     CodeInvalidArgument,
+				Message:  fmt.Sprintf("argument %d of %s is not a valid %s: %v", i+1, function, h.params[i], err),
+				Function: function,
+				Argument: i + 1,
+			}).Response()
+		}
+		in = append(in, value)
+	}
+
+	return h.fn.Call(in)[0].Interface().(pb.Response)
+}
+
+// decodeArg turns arg into a value of type t
+func (r *Router) decodeArg(arg string, t reflect.Type) (reflect.Value, error) {
+	switch {
+	case t.Kind() == reflect.String:
+		return reflect.ValueOf(arg).Convert(t), nil
+	case t == bytesType:
+		return reflect.ValueOf([]byte(arg)), nil
+	}
+
+	ptr := reflect.New(t)
+	err := r.Decode([]byte(arg), ptr.Interface())
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return ptr.Elem(), nil
+}
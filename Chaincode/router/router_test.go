@@ -0,0 +1,129 @@
+package router
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+type point struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+func newTestRouter(t *testing.T) *Router {
+	t.Helper()
+	r := New()
+	handlers := map[string]interface{}{
+		"Echo": func(stub shim.ChaincodeStubInterface, s string) pb.Response {
+			return shim.Success([]byte(s))
+		},
+		"Move": func(stub shim.ChaincodeStubInterface, name string, p point) pb.Response {
+			return shim.Success([]byte(strings.Repeat(name, p.X+p.Y)))
+		},
+	}
+	for name, fn := range handlers {
+		if err := r.Register(name, fn); err != nil {
+			t.Fatalf("Register(%s) failed: %v", name, err)
+		}
+	}
+	return r
+}
+
+// routedChaincode is a chaincode whose Invoke is the router
+type routedChaincode struct {
+	*Router
+}
+
+func (cc routedChaincode) Init(stub shim.ChaincodeStubInterface) pb.Response {
+	return shim.Success(nil)
+}
+
+func (cc routedChaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
+	return cc.Handle(stub)
+}
+
+func handle(r *Router, args ...string) pb.Response {
+	byteArgs := make([][]byte, 0, len(args))
+	for _, arg := range args {
+		byteArgs = append(byteArgs, []byte(arg))
+	}
+	stub := shimtest.NewMockStub("router", routedChaincode{r})
+	return stub.MockInvoke("tx", byteArgs)
+}
+
+func TestRegister(t *testing.T) {
+	tests := []struct {
+		name    string
+		fn      interface{}
+		wantErr string
+	}{
+		{name: "not a func", fn: "Echo", wantErr: "not a func"},
+		{name: "no stub", fn: func(s string) pb.Response { return shim.Success(nil) }, wantErr: "first parameter"},
+		{name: "wrong result", fn: func(stub shim.ChaincodeStubInterface) error { return nil }, wantErr: "must return a pb.Response"},
+		{name: "variadic", fn: func(stub shim.ChaincodeStubInterface, s ...string) pb.Response { return shim.Success(nil) }, wantErr: "variadic"},
+		{name: "valid", fn: func(stub shim.ChaincodeStubInterface, s string, n int) pb.Response { return shim.Success(nil) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := New().Register("F", tt.fn)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Register failed: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHandle(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         []string
+		wantPayload  string
+		wantCode     string
+		wantArgument int
+	}{
+		{name: "string argument", args: []string{"Echo", "hello"}, wantPayload: "hello"},
+		{name: "json argument", args: []string{"Move", "a", `{"x":1,"y":2}`}, wantPayload: "aaa"},
+		{name: "unknown function", args: []string{"Jump"}, wantCode: CodeUnknownFunction},
+		{name: "too few arguments", args: []string{"Move", "a"}, wantCode: CodeWrongArgumentCount},
+		{name: "too many arguments", args: []string{"Echo", "a", "b"}, wantCode: CodeWrongArgumentCount},
+		{name: "invalid json", args: []string{"Move", "a", `{"x":"one"}`}, wantCode: CodeInvalidArgument, wantArgument: 2},
+	}
+
+	r := newTestRouter(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := handle(r, tt.args...)
+			if tt.wantCode == "" {
+				if response.Status != shim.OK {
+					t.Fatalf("status = %d (%s), want OK", response.Status, response.Message)
+				}
+				if string(response.Payload) != tt.wantPayload {
+					t.Errorf("payload = %s, want %s", response.Payload, tt.wantPayload)
+				}
+				return
+			}
+
+			routerErr, ok := ParseError(response.Message)
+			if !ok {
+				t.Fatalf("message %q is not a router error", response.Message)
+			}
+			if routerErr.Code != tt.wantCode || routerErr.Argument != tt.wantArgument {
+				t.Errorf("error = %+v, want code %s, argument %d", routerErr, tt.wantCode, tt.wantArgument)
+			}
+			if tt.wantCode == CodeUnknownFunction && strings.Join(routerErr.Available, ",") != "Echo,Move" {
+				t.Errorf("available = %v, want [Echo Move]", routerErr.Available)
+			}
+		})
+	}
+}
@@ -31,7 +31,7 @@ func receiver(ch <-chan string, doneCh chan<- bool) {
 	// Perform some processing with the received data
 
 	doneCh <- true // Signal that processing is complete
-	(recommended way for the last goroutine, so that the main function will wait until the last goroutine get executed)
+	// (recommended way for the last goroutine, so that the main function will wait until the last goroutine get executed)
 }
 
 func main() {
@@ -0,0 +1,32 @@
+// Command asset-highlevel starts the contractapi SimpleAssetChaincode.
+//
+// META-INF/statedb/couchdb/indexes holds the CouchDB indexes (owner, price, color) used by
+// its rich queries. They are packaged with the chaincode and created by the peer on install.
+package main
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/highlevel"
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/recovery"
+)
+
+func main() {
+	// Whether DeleteAsset keeps deleted assets in the world state is not configured here but in
+	// the ledger, by an admin with SetDeleteMode, so that every peer deletes the same way
+	SimpleAssetChaincodeContract := new(highlevel.SimpleAssetChaincode)
+
+	chaincode, err := contractapi.NewChaincode(SimpleAssetChaincodeContract)
+	if err != nil {
+		fmt.Printf("Error creating SimpleAsset chaincode: %s", err.Error())
+		return
+	}
+
+	// recovery.Wrap turns a panic in a contract function into the error of its transaction
+	if err := shim.Start(recovery.Wrap(chaincode)); err != nil {
+		fmt.Printf("Error starting SimpleAsset chaincode: %s", err.Error())
+	}
+}
@@ -0,0 +1,60 @@
+// Command asset-listener prints the asset events of an events file written by listener.Record.
+//
+//	asset-listener -events events.jsonl -checkpoint asset-listener.checkpoint
+//
+// It remembers the last event it printed in the checkpoint file, so running it again only
+// prints the events recorded since.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/assetevents"
+	"github.com/salilOffice-cmd/GoPrac/offchain/listener"
+)
+
+func main() {
+	eventsPath := flag.String("events", "", "events file to replay, one json event per line")
+	checkpointPath := flag.String("checkpoint", "asset-listener.checkpoint", "file keeping the last processed event")
+	flag.Parse()
+	if *eventsPath == "" {
+		fmt.Println("Usage: asset-listener -events <file> [-checkpoint <file>]")
+		os.Exit(2)
+	}
+
+	source, err := listener.OpenFileSource(*eventsPath)
+	if err != nil {
+		fmt.Printf("Error opening events: %v\n", err)
+		os.Exit(1)
+	}
+	defer source.Close()
+
+	l := listener.New(source, &listener.FileCheckpointer{Path: *checkpointPath})
+	for _, name := range assetevents.DefaultRegistry.Names() {
+		l.HandleAsset(name, printEvent)
+	}
+
+	if err := l.Run(context.Background()); err != nil {
+		fmt.Printf("Error listening for events: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// printEvent prints an asset event on one line
+func printEvent(ctx context.Context, event *listener.Event, assetEvent assetevents.Event) error {
+	header := assetEvent.EventHeader()
+	switch e := assetEvent.(type) {
+	case *assetevents.AssetCreated:
+		fmt.Printf("block %d: asset %s created by %s for %s\n", event.BlockNumber, e.AssetID, e.Actor.MSPID, e.After.Owner)
+	case *assetevents.AssetTransferred:
+		fmt.Printf("block %d: asset %s transferred from %s to %s\n", event.BlockNumber, e.AssetID, e.From, e.To)
+	case *assetevents.AssetDeleted:
+		fmt.Printf("block %d: asset %s deleted (soft: %v)\n", event.BlockNumber, e.AssetID, e.Soft)
+	default:
+		fmt.Printf("block %d: %s of asset %s\n", event.BlockNumber, header.Name, header.AssetID)
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+// Command asset-lowlevel starts the shim SimpleAssetChaincode.
+//
+// META-INF/statedb/couchdb/indexes holds the CouchDB indexes (owner, price, color) used by
+// its rich queries. They are packaged with the chaincode and created by the peer on install.
+package main
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/lowlevel"
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/recovery"
+)
+
+func main() {
+	// Whether DeleteAsset keeps deleted assets in the world state is not configured here but in
+	// the ledger, by an admin with SetDeleteMode, so that every peer deletes the same way
+	chaincode := new(lowlevel.SimpleAssetChaincode)
+
+	err := shim.Start(recovery.Wrap(chaincode))
+	if err != nil {
+		fmt.Printf("Error starting SimpleAsset chaincode: %s", err)
+	}
+}
@@ -0,0 +1,56 @@
+// Command asset-projector applies the asset events of an events file written by listener.Record
+// to the off-chain read model, then serves its reports over http for dashboards.
+//
+//	asset-projector -events events.jsonl -db readmodel.db -addr :8080
+//	curl localhost:8080/reports/value-per-owner
+//
+// See package readmodel for the queries it answers.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/salilOffice-cmd/GoPrac/offchain/listener"
+	"github.com/salilOffice-cmd/GoPrac/offchain/readmodel"
+)
+
+func main() {
+	eventsPath := flag.String("events", "", "events file to apply, one json event per line")
+	dbPath := flag.String("db", "readmodel.db", "read model database")
+	checkpointPath := flag.String("checkpoint", "asset-projector.checkpoint", "file keeping the last applied event")
+	addr := flag.String("addr", ":8080", "address of the query api")
+	flag.Parse()
+
+	store, err := readmodel.Open(*dbPath)
+	if err != nil {
+		fmt.Printf("Error opening read model: %v\n", err)
+		os.Exit(1)
+	}
+	defer store.Close()
+
+	if *eventsPath != "" {
+		source, err := listener.OpenFileSource(*eventsPath)
+		if err != nil {
+			fmt.Printf("Error opening events: %v\n", err)
+			os.Exit(1)
+		}
+		l := listener.New(source, &listener.FileCheckpointer{Path: *checkpointPath})
+		readmodel.NewProjector(store).Register(l)
+		err = l.Run(context.Background())
+		source.Close()
+		if err != nil {
+			fmt.Printf("Error applying events: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	fmt.Printf("Serving the read model on %s\n", *addr)
+	if err := http.ListenAndServe(*addr, readmodel.NewHandler(store)); err != nil {
+		fmt.Printf("Error serving the read model: %v\n", err)
+		os.Exit(1)
+	}
+}
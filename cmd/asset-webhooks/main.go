@@ -0,0 +1,68 @@
+// Command asset-webhooks delivers the events of an events file written by listener.Record
+// to the webhooks of a subscriptions file, a json array of webhook.Subscription:
+//
+//	[{"eventName":"AssetTransferred","url":"https://erp.example.com/hooks/asset","secret":"..."}]
+//
+//	asset-webhooks -events events.jsonl -subscriptions subscriptions.json
+//
+// It prints the delivery metrics of every event name when it is done.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/salilOffice-cmd/GoPrac/offchain/listener"
+	"github.com/salilOffice-cmd/GoPrac/offchain/webhook"
+)
+
+func main() {
+	eventsPath := flag.String("events", "", "events file to deliver, one json event per line")
+	subscriptionsPath := flag.String("subscriptions", "", "json file with the subscriptions")
+	checkpointPath := flag.String("checkpoint", "asset-webhooks.checkpoint", "file keeping the last delivered event")
+	deadLetterPath := flag.String("dead-letter", "asset-webhooks.deadletter", "file the failed deliveries are appended to")
+	flag.Parse()
+	if *eventsPath == "" || *subscriptionsPath == "" {
+		fmt.Println("Usage: asset-webhooks -events <file> -subscriptions <file> [-checkpoint <file>] [-dead-letter <file>]")
+		os.Exit(2)
+	}
+
+	subscriptionsJSON, err := os.ReadFile(*subscriptionsPath)
+	if err != nil {
+		fmt.Printf("Error reading subscriptions: %v\n", err)
+		os.Exit(1)
+	}
+	var subscriptions []webhook.Subscription
+	if err := json.Unmarshal(subscriptionsJSON, &subscriptions); err != nil {
+		fmt.Printf("Error reading subscriptions: %v\n", err)
+		os.Exit(1)
+	}
+	dispatcher := webhook.NewDispatcher(*deadLetterPath)
+	for _, subscription := range subscriptions {
+		if err := dispatcher.Subscribe(subscription); err != nil {
+			fmt.Printf("Error in subscriptions: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	source, err := listener.OpenFileSource(*eventsPath)
+	if err != nil {
+		fmt.Printf("Error opening events: %v\n", err)
+		os.Exit(1)
+	}
+	defer source.Close()
+	l := listener.New(source, &listener.FileCheckpointer{Path: *checkpointPath})
+	dispatcher.Register(l)
+	err = l.Run(context.Background())
+
+	for name, counters := range dispatcher.Metrics() {
+		fmt.Printf("%s: %d delivered, %d dead lettered, %d attempts (%d retries)\n", name, counters.Delivered, counters.DeadLettered, counters.Attempts, counters.Retries)
+	}
+	if err != nil {
+		fmt.Printf("Error delivering events: %v\n", err)
+		os.Exit(1)
+	}
+}
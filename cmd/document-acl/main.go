@@ -0,0 +1,25 @@
+// Command document-acl starts the contractapi DocumentChaincode.
+package main
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	accesscontrol "github.com/salilOffice-cmd/GoPrac/Chaincode/Access_Control_7"
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/recovery"
+)
+
+func main() {
+	chaincode, err := contractapi.NewChaincode(&accesscontrol.DocumentChaincode{})
+	if err != nil {
+		fmt.Printf("Error creating DocumentChaincode: %s", err)
+		return
+	}
+
+	// recovery.Wrap turns a panic in a contract function into the error of its transaction
+	if err := shim.Start(recovery.Wrap(chaincode)); err != nil {
+		fmt.Printf("Error starting chaincode: %s", err)
+	}
+}
@@ -0,0 +1,25 @@
+// Command events starts the SimpleAssetChaincode that emits the AssetCreated event.
+package main
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	events "github.com/salilOffice-cmd/GoPrac/Chaincode/Events_4"
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/recovery"
+)
+
+func main() {
+	chaincode, err := contractapi.NewChaincode(&events.SimpleAssetChaincode{})
+	if err != nil {
+		fmt.Printf("Error creating SimpleAssetChaincode: %v\n", err)
+		return
+	}
+
+	// recovery.Wrap turns a panic in a contract function into the error of its transaction
+	if err := shim.Start(recovery.Wrap(chaincode)); err != nil {
+		fmt.Printf("Error starting SimpleAssetChaincode: %v\n", err)
+	}
+}
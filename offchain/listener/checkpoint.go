@@ -0,0 +1,89 @@
+package listener
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Checkpoint is the position of the last event a Listener processed.
+// The zero Checkpoint means that no event was processed yet.
+type Checkpoint struct {
+	BlockNumber uint64 `json:"blockNumber"`
+	TxID        string `json:"txId"`
+}
+
+// Checkpointer keeps the Checkpoint of a Listener between two runs
+type Checkpointer interface {
+	// Load returns the saved checkpoint, or the zero Checkpoint if none was saved yet
+	Load() (Checkpoint, error)
+	// Save saves checkpoint in place of the previous one
+	Save(checkpoint Checkpoint) error
+}
+
+// MemoryCheckpointer keeps the checkpoint in memory, it only resumes a Listener within the same process
+type MemoryCheckpointer struct {
+	Checkpoint Checkpoint
+}
+
+// Load returns the saved checkpoint
+func (c *MemoryCheckpointer) Load() (Checkpoint, error) {
+	return c.Checkpoint, nil
+}
+
+// Save saves the checkpoint
+func (c *MemoryCheckpointer) Save(checkpoint Checkpoint) error {
+	c.Checkpoint = checkpoint
+	return nil
+}
+
+// FileCheckpointer keeps the checkpoint as json in the file Path
+type FileCheckpointer struct {
+	Path string
+}
+
+// Load reads the checkpoint from the file, a missing file is the zero Checkpoint
+func (c *FileCheckpointer) Load() (Checkpoint, error) {
+	var checkpoint Checkpoint
+	checkpointJSON, err := os.ReadFile(c.Path)
+	if os.IsNotExist(err) {
+		return checkpoint, nil
+	}
+	if err != nil {
+		return checkpoint, fmt.Errorf("failed to read checkpoint: %v", err)
+	}
+	err = json.Unmarshal(checkpointJSON, &checkpoint)
+	if err != nil {
+		return checkpoint, fmt.Errorf("failed to unmarshal checkpoint %s: %v", c.Path, err)
+	}
+	return checkpoint, nil
+}
+
+// Save writes the checkpoint to a temporary file and renames it over the file,
+// so that a crash in the middle of Save leaves the previous checkpoint and never half of one
+func (c *FileCheckpointer) Save(checkpoint Checkpoint) error {
+	checkpointJSON, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %v", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.Path), filepath.Base(c.Path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to save checkpoint: %v", err)
+	}
+	_, err = tmp.Write(checkpointJSON)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to save checkpoint: %v", err)
+	}
+	err = os.Rename(tmp.Name(), c.Path)
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to save checkpoint: %v", err)
+	}
+	return nil
+}
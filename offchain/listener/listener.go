@@ -0,0 +1,169 @@
+// Package listener delivers the chaincode events of the SimpleAssetChaincodes to off-chain services.
+//
+// A Listener reads the events of a Source, hands every event to the handlers registered for its
+// name and saves a Checkpoint after each event, so that a restarted listener goes on where it stopped:
+//
+//	l := listener.New(source, &listener.FileCheckpointer{Path: "listener.checkpoint"})
+//	l.HandleAsset(assetevents.NameAssetCreated, func(ctx context.Context, event *listener.Event, assetEvent assetevents.Event) error {
+//		created := assetEvent.(*assetevents.AssetCreated)
+//		fmt.Printf("New asset created with ID: %s in transaction %s\n", created.AssetID, event.TxID)
+//		return nil
+//	})
+//	err := l.Run(ctx)
+//
+// The Source hides where the events come from: a peer in production, and offline a FileSource
+// replaying events recorded to a file, or a ChannelSource reading the events emitted in-process
+// by a chaincode running on a MockStub.
+package listener
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/assetevents"
+)
+
+// Event is a chaincode event with the block and the transaction that emitted it
+type Event struct {
+	BlockNumber uint64 `json:"blockNumber"`
+	TxID        string `json:"txId"`
+	ChaincodeID string `json:"chaincodeId"`
+	EventName   string `json:"eventName"`
+	Payload     []byte `json:"payload"`
+}
+
+// NewEvent returns the Event of a chaincode event of the block blockNumber
+func NewEvent(blockNumber uint64, chaincodeEvent *pb.ChaincodeEvent) *Event {
+	return &Event{
+		BlockNumber: blockNumber,
+		TxID:        chaincodeEvent.TxId,
+		ChaincodeID: chaincodeEvent.ChaincodeId,
+		EventName:   chaincodeEvent.EventName,
+		Payload:     chaincodeEvent.Payload,
+	}
+}
+
+// Handler handles a chaincode event.
+// An error stops the Listener before the event is checkpointed, so the event is delivered again on the next Run.
+type Handler func(ctx context.Context, event *Event) error
+
+// AssetHandler handles an asset event (see package assetevents) together with the chaincode event that carried it
+type AssetHandler func(ctx context.Context, event *Event, assetEvent assetevents.Event) error
+
+// Listener delivers the events of a Source to the registered handlers
+type Listener struct {
+	source       Source
+	checkpointer Checkpointer
+
+	handlers      map[string][]Handler
+	assetHandlers map[string][]AssetHandler
+
+	// Registry decodes the asset events for the asset handlers.
+	// It defaults to assetevents.DefaultRegistry.
+	Registry *assetevents.Registry
+}
+
+// New creates a Listener that reads the events of source and keeps its checkpoint with checkpointer
+func New(source Source, checkpointer Checkpointer) *Listener {
+	return &Listener{
+		source:        source,
+		checkpointer:  checkpointer,
+		handlers:      make(map[string][]Handler),
+		assetHandlers: make(map[string][]AssetHandler),
+		Registry:      assetevents.DefaultRegistry,
+	}
+}
+
+// Handle registers handler for the chaincode events named eventName.
+// The handlers of a name are called in the order they were registered.
+func (l *Listener) Handle(eventName string, handler Handler) {
+	l.handlers[eventName] = append(l.handlers[eventName], handler)
+}
+
+// HandleAsset registers handler for the asset events named name (AssetCreated, AssetTransferred, ...).
+// The asset events are found in the AssetEvents envelopes as well as in the events emitted on their own.
+func (l *Listener) HandleAsset(name string, handler AssetHandler) {
+	l.assetHandlers[name] = append(l.assetHandlers[name], handler)
+}
+
+// Run delivers the events that follow the checkpoint until the source has no more events,
+// the context is done or a handler fails. Every delivered event is checkpointed, including
+// the events that no handler is registered for.
+func (l *Listener) Run(ctx context.Context) error {
+	checkpoint, err := l.checkpointer.Load()
+	if err != nil {
+		return err
+	}
+
+	// The source may start over from the first event, or from the start of the block of the
+	// checkpoint: skip everything up to the checkpointed transaction
+	skipping := checkpoint.TxID != ""
+	for {
+		event, err := l.source.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read event: %v", err)
+		}
+
+		if skipping {
+			if event.BlockNumber < checkpoint.BlockNumber {
+				continue
+			}
+			if event.BlockNumber == checkpoint.BlockNumber {
+				if event.TxID == checkpoint.TxID {
+					skipping = false
+				}
+				continue
+			}
+			skipping = false
+		}
+
+		err = l.dispatch(ctx, event)
+		if err != nil {
+			return fmt.Errorf("failed to handle event %s of transaction %s: %v", event.EventName, event.TxID, err)
+		}
+		checkpoint = Checkpoint{BlockNumber: event.BlockNumber, TxID: event.TxID}
+		err = l.checkpointer.Save(checkpoint)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// dispatch hands event to its handlers, then the asset events it carries to their asset handlers
+func (l *Listener) dispatch(ctx context.Context, event *Event) error {
+	for _, handler := range l.handlers[event.EventName] {
+		err := handler(ctx, event)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Only decode the events that may carry an asset event somebody listens to, the other
+	// events (of other chaincodes, ...) are not asset events
+	if len(l.assetHandlers) == 0 {
+		return nil
+	}
+	if _, ok := l.assetHandlers[event.EventName]; !ok && event.EventName != assetevents.NameAssetEvents {
+		return nil
+	}
+	assetEvents, err := l.Registry.Unpack(event.EventName, event.Payload)
+	if err != nil {
+		return err
+	}
+	for _, assetEvent := range assetEvents {
+		for _, handler := range l.assetHandlers[assetEvent.EventHeader().Name] {
+			err := handler(ctx, event, assetEvent)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
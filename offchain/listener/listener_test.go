@@ -0,0 +1,248 @@
+package listener
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/assetevents"
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/chaincodetest"
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/highlevel"
+)
+
+func newStub(t *testing.T) *chaincodetest.Stub {
+	t.Helper()
+	chaincode, err := contractapi.NewChaincode(new(highlevel.SimpleAssetChaincode))
+	if err != nil {
+		t.Fatalf("failed to create chaincode: %v", err)
+	}
+	stub := chaincodetest.NewStub("asset", chaincode)
+	setCreator(t, stub, "Alice", nil)
+	return stub
+}
+
+// setCreator makes the client of Org1MSP with the common name client the creator of the next transactions
+func setCreator(t *testing.T, stub *chaincodetest.Stub, client string, attrs map[string]string) {
+	t.Helper()
+	ci, err := chaincodetest.NewClientIdentity("Org1MSP", client, attrs)
+	if err != nil {
+		t.Fatalf("failed to create identity: %v", err)
+	}
+	if err := stub.SetCreator(ci); err != nil {
+		t.Fatalf("failed to set creator: %v", err)
+	}
+}
+
+func invoke(t *testing.T, stub *chaincodetest.Stub, txID string, args ...string) {
+	t.Helper()
+	byteArgs := make([][]byte, 0, len(args))
+	for _, arg := range args {
+		byteArgs = append(byteArgs, []byte(arg))
+	}
+	if response := stub.MockInvoke(txID, byteArgs); response.Status != shim.OK {
+		t.Fatalf("%s failed: %s", args[0], response.Message)
+	}
+}
+
+// recorder is a listener that writes down the assets of the events it handles
+type recorder struct {
+	created     []string
+	transferred []string
+	envelopes   []string
+}
+
+func (r *recorder) listen(source Source, checkpointer Checkpointer) *Listener {
+	l := New(source, checkpointer)
+	l.Handle(assetevents.NameAssetEvents, func(ctx context.Context, event *Event) error {
+		r.envelopes = append(r.envelopes, event.TxID)
+		return nil
+	})
+	l.HandleAsset(assetevents.NameAssetCreated, func(ctx context.Context, event *Event, assetEvent assetevents.Event) error {
+		r.created = append(r.created, assetEvent.EventHeader().AssetID)
+		return nil
+	})
+	l.HandleAsset(assetevents.NameAssetTransferred, func(ctx context.Context, event *Event, assetEvent assetevents.Event) error {
+		transferred := assetEvent.(*assetevents.AssetTransferred)
+		r.transferred = append(r.transferred, transferred.AssetID+" to "+transferred.To)
+		return nil
+	})
+	return l
+}
+
+func TestReplayRecordedEvents(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	eventsPath := filepath.Join(dir, "events.jsonl")
+	checkpointer := &FileCheckpointer{Path: filepath.Join(dir, "checkpoint.json")}
+
+	stub := newStub(t)
+	setCreator(t, stub, "admin", map[string]string{"role": "admin"})
+	invoke(t, stub, "init", "InitLedger")
+	setCreator(t, stub, "Carol", nil)
+	invoke(t, stub, "create", "CreateAsset", `{"ID":"asset3","owner":"Carol","color":"green","size":7,"price":300}`)
+	// the assets of InitLedger have no owner identity, an admin hands them over
+	setCreator(t, stub, "Bob", nil)
+	invoke(t, stub, "register", "RegisterDisplayName", "Bob")
+	setCreator(t, stub, "admin", map[string]string{"role": "admin"})
+	invoke(t, stub, "transfer", "TransferAssetOwnership", "asset1", "Bob", "Org1MSP")
+	// the source numbers the transactions of the stub as blocks 1, 2, 3, ...
+	source := NewChannelSource(stub.ChaincodeEventsChannel)
+	recorded, err := Record(ctx, eventsPath, source)
+	if err != nil || recorded != 3 {
+		t.Fatalf("Record = %d, %v, want 3 events", recorded, err)
+	}
+
+	replay := func(r *recorder) {
+		t.Helper()
+		source, err := OpenFileSource(eventsPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer source.Close()
+		if err := r.listen(source, checkpointer).Run(ctx); err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+	}
+
+	first := new(recorder)
+	replay(first)
+	if want := []string{"asset1", "asset2", "asset3"}; !reflect.DeepEqual(first.created, want) {
+		t.Errorf("created = %v, want %v", first.created, want)
+	}
+	if want := []string{"asset1 to Bob"}; !reflect.DeepEqual(first.transferred, want) {
+		t.Errorf("transferred = %v, want %v", first.transferred, want)
+	}
+	if want := []string{"init", "create", "transfer"}; !reflect.DeepEqual(first.envelopes, want) {
+		t.Errorf("envelopes = %v, want one per transaction %v", first.envelopes, want)
+	}
+	if checkpoint, _ := checkpointer.Load(); checkpoint != (Checkpoint{BlockNumber: 3, TxID: "transfer"}) {
+		t.Errorf("checkpoint = %+v, want block 3 transaction transfer", checkpoint)
+	}
+
+	// a listener started again only gets the events recorded since, even by a recorder
+	// started again with a new source that numbers the blocks from 1
+	setCreator(t, stub, "Alice", nil)
+	invoke(t, stub, "create asset4", "CreateAsset", `{"ID":"asset4","owner":"Alice","color":"red","size":1,"price":10}`)
+	if _, err := Record(ctx, eventsPath, NewChannelSource(stub.ChaincodeEventsChannel)); err != nil {
+		t.Fatal(err)
+	}
+	if blocks := recordedBlocks(t, eventsPath); !reflect.DeepEqual(blocks, []uint64{1, 2, 3, 4}) {
+		t.Errorf("recorded blocks = %v, want 1, 2, 3, 4", blocks)
+	}
+	second := new(recorder)
+	replay(second)
+	if want := []string{"asset4"}; !reflect.DeepEqual(second.created, want) || len(second.transferred) != 0 {
+		t.Errorf("second run got created %v and transferred %v, want only asset4 created", second.created, second.transferred)
+	}
+}
+
+// cancelAfter cancels the context of the recording once n events were read from the source,
+// like a recorder stopped while it follows a source
+type cancelAfter struct {
+	Source
+	n      int
+	cancel context.CancelFunc
+}
+
+func (s *cancelAfter) Next(ctx context.Context) (*Event, error) {
+	if s.n == 0 {
+		s.cancel()
+	}
+	s.n--
+	return s.Source.Next(ctx)
+}
+
+func TestRecordCancelled(t *testing.T) {
+	eventsPath := filepath.Join(t.TempDir(), "events.jsonl")
+	stub := newStub(t)
+	invoke(t, stub, "create asset1", "CreateAsset", `{"ID":"asset1","owner":"Alice","color":"red","size":1,"price":10}`)
+	invoke(t, stub, "create asset2", "CreateAsset", `{"ID":"asset2","owner":"Alice","color":"blue","size":2,"price":20}`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	source := NewChannelSource(stub.ChaincodeEventsChannel)
+	source.Follow = true
+	recorded, err := Record(ctx, eventsPath, &cancelAfter{Source: source, n: 2, cancel: cancel})
+	if err == nil || !strings.Contains(err.Error(), context.Canceled.Error()) || recorded != 2 {
+		t.Fatalf("Record = %d, %v, want 2 events and the context error", recorded, err)
+	}
+
+	// the events recorded before the cancel are in the file
+	if blocks := recordedBlocks(t, eventsPath); !reflect.DeepEqual(blocks, []uint64{1, 2}) {
+		t.Errorf("recorded blocks = %v, want 1, 2", blocks)
+	}
+}
+
+// recordedBlocks returns the block numbers of the events of the events file path
+func recordedBlocks(t *testing.T, path string) []uint64 {
+	t.Helper()
+	source, err := OpenFileSource(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer source.Close()
+	var blocks []uint64
+	for {
+		event, err := source.Next(context.Background())
+		if errors.Is(err, io.EOF) {
+			return blocks
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		blocks = append(blocks, event.BlockNumber)
+	}
+}
+
+func TestCheckpointResume(t *testing.T) {
+	events := []*Event{
+		{BlockNumber: 4, TxID: "a", EventName: "Ping"},
+		{BlockNumber: 5, TxID: "b", EventName: "Ping"},
+		{BlockNumber: 5, TxID: "c", EventName: "Ping"},
+		{BlockNumber: 6, TxID: "d", EventName: "Other"},
+		{BlockNumber: 7, TxID: "e", EventName: "Ping"},
+	}
+	checkpointer := &MemoryCheckpointer{Checkpoint: Checkpoint{BlockNumber: 5, TxID: "b"}}
+
+	var handled []string
+	failOn := "e"
+	run := func() error {
+		l := New(NewSliceSource(events...), checkpointer)
+		l.Handle("Ping", func(ctx context.Context, event *Event) error {
+			if event.TxID == failOn {
+				return errors.New("handler is down")
+			}
+			handled = append(handled, event.TxID)
+			return nil
+		})
+		return l.Run(context.Background())
+	}
+
+	// the source starts over from block 4, the events up to b in block 5 were processed already
+	err := run()
+	if err == nil || !strings.Contains(err.Error(), "failed to handle event Ping of transaction e: handler is down") {
+		t.Fatalf("Run = %v, want the error of the handler", err)
+	}
+	if want := []string{"c"}; !reflect.DeepEqual(handled, want) {
+		t.Errorf("handled = %v, want %v", handled, want)
+	}
+	// d has no handler and is checkpointed all the same, e failed and is not
+	if want := (Checkpoint{BlockNumber: 6, TxID: "d"}); checkpointer.Checkpoint != want {
+		t.Errorf("checkpoint = %+v, want %+v", checkpointer.Checkpoint, want)
+	}
+
+	failOn = ""
+	if err := run(); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if want := []string{"c", "e"}; !reflect.DeepEqual(handled, want) {
+		t.Errorf("handled = %v, want e delivered again, %v", handled, want)
+	}
+}
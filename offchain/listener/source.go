@@ -0,0 +1,211 @@
+package listener
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// Source delivers chaincode events in the order of the ledger
+type Source interface {
+	// Next returns the next event. It returns io.EOF when the source has no more events,
+	// and ctx.Err() if the context is done while it waits for one.
+	Next(ctx context.Context) (*Event, error)
+}
+
+// SliceSource replays the events of a slice
+type SliceSource struct {
+	events []*Event
+}
+
+// NewSliceSource creates a SliceSource of events
+func NewSliceSource(events ...*Event) *SliceSource {
+	return &SliceSource{events: events}
+}
+
+// Next returns the next event of the slice
+func (s *SliceSource) Next(ctx context.Context) (*Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(s.events) == 0 {
+		return nil, io.EOF
+	}
+	event := s.events[0]
+	s.events = s.events[1:]
+	return event, nil
+}
+
+// ChannelSource reads the events of a chaincode running in-process on a MockStub, from its
+// ChaincodeEventsChannel. A MockStub has no blocks, so every event gets the next block number,
+// starting from block 1 (or after the block given to ContinueAfter).
+type ChannelSource struct {
+	events      <-chan *pb.ChaincodeEvent
+	blockNumber uint64
+
+	// Follow makes Next wait for the next event until the context is done.
+	// By default Next returns io.EOF as soon as the channel is empty.
+	Follow bool
+}
+
+// NewChannelSource creates a ChannelSource reading events, for example stub.ChaincodeEventsChannel
+func NewChannelSource(events <-chan *pb.ChaincodeEvent) *ChannelSource {
+	return &ChannelSource{events: events}
+}
+
+// ContinueAfter makes the source number the next event after blockNumber, if it would
+// number it lower. Record calls it with the last block of the file it appends to.
+func (s *ChannelSource) ContinueAfter(blockNumber uint64) {
+	if s.blockNumber < blockNumber {
+		s.blockNumber = blockNumber
+	}
+}
+
+// Next returns the next event of the channel
+func (s *ChannelSource) Next(ctx context.Context) (*Event, error) {
+	var chaincodeEvent *pb.ChaincodeEvent
+	var ok bool
+	if s.Follow {
+		select {
+		case chaincodeEvent, ok = <-s.events:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	} else {
+		select {
+		case chaincodeEvent, ok = <-s.events:
+		default:
+			return nil, io.EOF
+		}
+	}
+	if !ok {
+		return nil, io.EOF
+	}
+
+	s.blockNumber++
+	return NewEvent(s.blockNumber, chaincodeEvent), nil
+}
+
+// FileSource replays the events of a file written by Record, one json Event per line
+type FileSource struct {
+	file    *os.File
+	scanner *bufio.Scanner
+	line    int
+}
+
+// OpenFileSource opens the events file path
+func OpenFileSource(path string) (*FileSource, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open events file: %v", err)
+	}
+	scanner := bufio.NewScanner(file)
+	// an event carries whole assets, allow lines longer than the default 64KB
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	return &FileSource{file: file, scanner: scanner}, nil
+}
+
+// Next returns the event of the next line of the file
+func (s *FileSource) Next(ctx context.Context) (*Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if !s.scanner.Scan() {
+		if err := s.scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read events file: %v", err)
+		}
+		return nil, io.EOF
+	}
+	s.line++
+
+	event := new(Event)
+	err := json.Unmarshal(s.scanner.Bytes(), event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal event on line %d of %s: %v", s.line, s.file.Name(), err)
+	}
+	return event, nil
+}
+
+// Close closes the file
+func (s *FileSource) Close() error {
+	return s.file.Close()
+}
+
+// Record appends the events of source to the events file path until the source has no more events,
+// so that a FileSource can replay them later. It returns the number of events recorded, also when
+// it stops on an error (a cancelled ctx, for example): the events counted are in the file.
+//
+// A source that numbers the blocks itself (a ChannelSource started again, for example) would start
+// over from block 1, and a listener replaying the file would take the new events for old ones
+// it checkpointed already. So Record makes such a source continue after the last recorded block.
+func Record(ctx context.Context, path string, source Source) (recorded int, err error) {
+	if numbering, ok := source.(interface{ ContinueAfter(blockNumber uint64) }); ok {
+		last, err := lastRecordedBlock(path)
+		if err != nil {
+			return 0, err
+		}
+		numbering.ContinueAfter(last)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open events file: %v", err)
+	}
+	writer := bufio.NewWriter(file)
+	defer func() {
+		flushErr := writer.Flush()
+		if flushErr != nil {
+			flushErr = fmt.Errorf("failed to write events file: %v", flushErr)
+		}
+		err = errors.Join(err, flushErr, file.Close())
+	}()
+
+	encoder := json.NewEncoder(writer)
+	for {
+		event, err := source.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return recorded, fmt.Errorf("failed to read event: %v", err)
+		}
+		// Encode writes the event followed by a newline
+		err = encoder.Encode(event)
+		if err != nil {
+			return recorded, fmt.Errorf("failed to write event: %v", err)
+		}
+		recorded++
+	}
+	return recorded, nil
+}
+
+// lastRecordedBlock returns the block number of the last event of the events file path,
+// 0 if the file does not exist or is empty
+func lastRecordedBlock(path string) (uint64, error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	source, err := OpenFileSource(path)
+	if err != nil {
+		return 0, err
+	}
+	defer source.Close()
+
+	var last uint64
+	for {
+		event, err := source.Next(context.Background())
+		if errors.Is(err, io.EOF) {
+			return last, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		last = event.BlockNumber
+	}
+}
@@ -0,0 +1,100 @@
+package readmodel
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Logger receives the errors the handler can't send to the client anymore,
+// like a response that failed to be written. It writes to stderr by default.
+var Logger = log.New(os.Stderr, "", log.LstdFlags)
+
+// NewHandler serves the read model as json for dashboards:
+//
+//	GET /assets/{id}                  the asset
+//	GET /assets?owner=Alice           the assets of an owner
+//	GET /assets?color=red             the assets of a color
+//	GET /reports/value-per-owner      the number and total price of the assets of every owner
+//	GET /reports/assets-per-color     the number of assets of every color
+//
+// Errors are sent as {"error": "..."}.
+func NewHandler(store *Store) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/assets", func(w http.ResponseWriter, r *http.Request) {
+		if !allowGet(w, r) {
+			return
+		}
+		query := r.URL.Query()
+		switch {
+		case query.Get("owner") != "":
+			assets, err := store.AssetsByOwner(query.Get("owner"))
+			respond(w, assets, err)
+		case query.Get("color") != "":
+			assets, err := store.AssetsByColor(query.Get("color"))
+			respond(w, assets, err)
+		default:
+			writeError(w, http.StatusBadRequest, errors.New("expecting an owner or a color query parameter"))
+		}
+	})
+	mux.HandleFunc("/assets/", func(w http.ResponseWriter, r *http.Request) {
+		if !allowGet(w, r) {
+			return
+		}
+		assetID := strings.TrimPrefix(r.URL.Path, "/assets/")
+		a, err := store.Asset(assetID)
+		if errors.Is(err, ErrNotFound) {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		respond(w, a, err)
+	})
+	mux.HandleFunc("/reports/value-per-owner", func(w http.ResponseWriter, r *http.Request) {
+		if allowGet(w, r) {
+			report, err := store.ValuePerOwner()
+			respond(w, report, err)
+		}
+	})
+	mux.HandleFunc("/reports/assets-per-color", func(w http.ResponseWriter, r *http.Request) {
+		if allowGet(w, r) {
+			report, err := store.AssetsPerColor()
+			respond(w, report, err)
+		}
+	})
+	return mux
+}
+
+// allowGet answers 405 to anything but a GET, the read model is read only
+func allowGet(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method == http.MethodGet {
+		return true
+	}
+	w.Header().Set("Allow", http.MethodGet)
+	writeError(w, http.StatusMethodNotAllowed, errors.New("the read model only answers GET requests"))
+	return false
+}
+
+// respond sends v as json, or err as a 500
+func respond(w http.ResponseWriter, v interface{}, err error) {
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	// the status is sent already, so the client can't be told, only the log
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		Logger.Printf("failed to write response: %v", err)
+	}
+}
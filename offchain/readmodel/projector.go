@@ -0,0 +1,88 @@
+package readmodel
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/assetevents"
+	"github.com/salilOffice-cmd/GoPrac/offchain/listener"
+)
+
+// Projector applies the asset events delivered by a listener to a Store
+//
+//	p := readmodel.NewProjector(store)
+//	p.Register(l)
+//	err := l.Run(ctx)
+//
+// It is idempotent: the events of a transaction are applied together with the ID of the
+// transaction, and a transaction that was applied already is skipped. So a listener that
+// delivers events again (from an older checkpoint, or a lost one) leaves the read model as it is.
+type Projector struct {
+	store *Store
+
+	// Registry decodes the asset events, it defaults to assetevents.DefaultRegistry
+	Registry *assetevents.Registry
+}
+
+// NewProjector creates a Projector writing to store
+func NewProjector(store *Store) *Projector {
+	return &Projector{store: store, Registry: assetevents.DefaultRegistry}
+}
+
+// Register registers the projector with l for the AssetEvents envelopes
+// and the asset events emitted on their own
+func (p *Projector) Register(l *listener.Listener) {
+	l.Handle(assetevents.NameAssetEvents, p.Handle)
+	for _, name := range p.Registry.Names() {
+		l.Handle(name, p.Handle)
+	}
+}
+
+// Handle applies the asset events of a chaincode event to the read model in one database transaction,
+// unless the transaction of the event was applied already
+func (p *Projector) Handle(ctx context.Context, event *listener.Event) error {
+	assetEvents, err := p.Registry.Unpack(event.EventName, event.Payload)
+	if err != nil {
+		return err
+	}
+
+	return p.store.db.Update(func(tx *bolt.Tx) error {
+		transactions := tx.Bucket(transactionsBucket)
+		if transactions.Get([]byte(event.TxID)) != nil {
+			return nil
+		}
+		for _, assetEvent := range assetEvents {
+			err := apply(tx, assetEvent)
+			if err != nil {
+				return fmt.Errorf("failed to apply %s event of asset %s: %v", assetEvent.EventHeader().Name, assetEvent.EventHeader().AssetID, err)
+			}
+		}
+		return transactions.Put([]byte(event.TxID), []byte(strconv.FormatUint(event.BlockNumber, 10)))
+	})
+}
+
+// apply writes the asset after the event: a soft deleted asset is kept with the status deleted
+// (the reports leave it out), an asset deleted for good is removed
+func apply(tx *bolt.Tx, assetEvent assetevents.Event) error {
+	switch e := assetEvent.(type) {
+	case *assetevents.AssetCreated:
+		return putAsset(tx, e.After)
+	case *assetevents.AssetUpdated:
+		return putAsset(tx, e.After)
+	case *assetevents.AssetTransferred:
+		return putAsset(tx, e.After)
+	case *assetevents.AssetDeleted:
+		if e.Soft {
+			return putAsset(tx, e.After)
+		}
+		return deleteAsset(tx, e.AssetID)
+	case *assetevents.TransferProposed, *assetevents.TransferAccepted, *assetevents.TransferRejected:
+		// the offers are not part of the read model, an accepted one comes with its AssetTransferred event
+		return nil
+	default:
+		return fmt.Errorf("unexpected event type %T", assetEvent)
+	}
+}
@@ -0,0 +1,226 @@
+package readmodel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/chaincodetest"
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/highlevel"
+	"github.com/salilOffice-cmd/GoPrac/offchain/listener"
+)
+
+// chaincodeEvents runs a few transactions on the high level chaincode (with soft delete)
+// and returns the events they emitted
+func chaincodeEvents(t *testing.T) []*listener.Event {
+	t.Helper()
+	chaincode, err := contractapi.NewChaincode(new(highlevel.SimpleAssetChaincode))
+	if err != nil {
+		t.Fatalf("failed to create chaincode: %v", err)
+	}
+	stub := chaincodetest.NewStub("asset", chaincode)
+	admin, err := chaincodetest.NewClientIdentity("Org1MSP", "admin", map[string]string{"role": "admin"})
+	if err != nil {
+		t.Fatalf("failed to create identity: %v", err)
+	}
+	if err := stub.SetCreator(admin); err != nil {
+		t.Fatalf("failed to set creator: %v", err)
+	}
+	if response := stub.MockInvoke("setup", [][]byte{[]byte("SetDeleteMode"), []byte("soft")}); response.Status != shim.OK {
+		t.Fatalf("SetDeleteMode failed: %s", response.Message)
+	}
+
+	clients := map[string]*chaincodetest.ClientIdentity{"admin": admin}
+	for _, name := range []string{"Bob", "Carol"} {
+		ci, err := chaincodetest.NewClientIdentity("Org1MSP", name, nil)
+		if err != nil {
+			t.Fatalf("failed to create identity: %v", err)
+		}
+		clients[name] = ci
+	}
+	if err := stub.SetCreator(clients["Bob"]); err != nil {
+		t.Fatalf("failed to set creator: %v", err)
+	}
+	if response := stub.MockInvoke("setup", [][]byte{[]byte("RegisterDisplayName"), []byte("Bob")}); response.Status != shim.OK {
+		t.Fatalf("RegisterDisplayName failed: %s", response.Message)
+	}
+
+	// the assets of InitLedger have no owner identity, the admin hands them over and changes them
+	transactions := []struct {
+		client string
+		args   []string
+	}{
+		{"admin", []string{"InitLedger"}},
+		{"Carol", []string{"CreateAsset", `{"ID":"asset3","owner":"Carol","color":"green","size":7,"price":300}`}},
+		{"admin", []string{"TransferAssetOwnership", "asset1", "Bob", "Org1MSP"}},
+		{"admin", []string{"UpdateAsset", `{"ID":"asset2","owner":"Bob","color":"red","size":10,"price":250}`}},
+		{"Carol", []string{"DeleteAsset", "asset3"}},
+	}
+	for i, tx := range transactions {
+		if err := stub.SetCreator(clients[tx.client]); err != nil {
+			t.Fatalf("failed to set creator: %v", err)
+		}
+		byteArgs := make([][]byte, 0, len(tx.args))
+		for _, arg := range tx.args {
+			byteArgs = append(byteArgs, []byte(arg))
+		}
+		if response := stub.MockInvoke(fmt.Sprintf("tx%d", i), byteArgs); response.Status != shim.OK {
+			t.Fatalf("%s failed: %s", tx.args[0], response.Message)
+		}
+	}
+
+	var events []*listener.Event
+	source := listener.NewChannelSource(stub.ChaincodeEventsChannel)
+	for {
+		event, err := source.Next(context.Background())
+		if errors.Is(err, io.EOF) {
+			return events
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, event)
+	}
+}
+
+// project runs a listener with a fresh checkpoint, that delivers every event, into store
+func project(t *testing.T, store *Store, events []*listener.Event) {
+	t.Helper()
+	l := listener.New(listener.NewSliceSource(events...), new(listener.MemoryCheckpointer))
+	NewProjector(store).Register(l)
+	if err := l.Run(context.Background()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+}
+
+func openStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := Open(filepath.Join(t.TempDir(), "readmodel.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestProjector(t *testing.T) {
+	store := openStore(t)
+	events := chaincodeEvents(t)
+
+	// the second run delivers every event again, as after a lost checkpoint
+	project(t, store, events)
+	project(t, store, events)
+
+	valuePerOwner, err := store.ValuePerOwner()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// asset1 went to Bob, asset2 changed price and asset3 was deleted
+	if want := []*OwnerValue{{Owner: "Bob", Assets: 2, TotalValue: 350}}; !reflect.DeepEqual(valuePerOwner, want) {
+		t.Errorf("value per owner = %s, want %s", toJSON(valuePerOwner), toJSON(want))
+	}
+	assetsPerColor, err := store.AssetsPerColor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []*ColorCount{{Color: "red", Assets: 2}}; !reflect.DeepEqual(assetsPerColor, want) {
+		t.Errorf("assets per color = %s, want %s", toJSON(assetsPerColor), toJSON(want))
+	}
+
+	byOwner, err := store.AssetsByOwner("Alice")
+	if err != nil || len(byOwner) != 0 {
+		t.Errorf("assets of Alice = %s, %v, want none after the transfer", toJSON(byOwner), err)
+	}
+	byColor, err := store.AssetsByColor("red")
+	if err != nil || len(byColor) != 2 || byColor[0].ID != "asset1" || byColor[1].ID != "asset2" || byColor[1].Version != 2 {
+		t.Errorf("red assets = %s, %v, want asset1 and version 2 of asset2", toJSON(byColor), err)
+	}
+	deleted, err := store.Asset("asset3")
+	if err != nil || !deleted.IsDeleted() {
+		t.Errorf("asset3 = %s, %v, want it soft deleted", toJSON(deleted), err)
+	}
+	if _, err := store.Asset("asset9"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Asset(asset9) = %v, want ErrNotFound", err)
+	}
+	if applied, err := store.Applied("tx4"); !applied || err != nil {
+		t.Errorf("Applied(tx4) = %v, %v, want the delete applied", applied, err)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	store := openStore(t)
+	project(t, store, chaincodeEvents(t))
+	server := httptest.NewServer(NewHandler(store))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"value per owner", http.MethodGet, "/reports/value-per-owner", http.StatusOK, `[{"owner":"Bob","assets":2,"totalValue":350}]`},
+		{"assets per color", http.MethodGet, "/reports/assets-per-color", http.StatusOK, `[{"color":"red","assets":2}]`},
+		{"assets by owner", http.MethodGet, "/assets?owner=Carol", http.StatusOK, `[]`},
+		{"asset", http.MethodGet, "/assets/asset1", http.StatusOK, `"owner":"Bob"`},
+		{"unknown asset", http.MethodGet, "/assets/asset9", http.StatusNotFound, `{"error":"asset not found"}`},
+		{"no filter", http.MethodGet, "/assets", http.StatusBadRequest, `expecting an owner or a color`},
+		{"write", http.MethodPost, "/assets/asset1", http.StatusMethodNotAllowed, `only answers GET requests`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := http.NewRequest(tt.method, server.URL+tt.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			response, err := http.DefaultClient.Do(request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer response.Body.Close()
+			body, _ := io.ReadAll(response.Body)
+			if response.StatusCode != tt.wantStatus || !strings.Contains(string(body), tt.wantBody) {
+				t.Errorf("%s %s = %d %s, want %d with %s", tt.method, tt.path, response.StatusCode, body, tt.wantStatus, tt.wantBody)
+			}
+		})
+	}
+}
+
+// brokenWriter is a client that went away: every write of the body fails
+type brokenWriter struct {
+	*httptest.ResponseRecorder
+}
+
+func (w brokenWriter) Write([]byte) (int, error) {
+	return 0, errors.New("connection reset by peer")
+}
+
+func TestWriteJSONLogsFailedWrite(t *testing.T) {
+	var logged bytes.Buffer
+	defer func(logger *log.Logger) { Logger = logger }(Logger)
+	Logger = log.New(&logged, "", 0)
+
+	writeJSON(brokenWriter{httptest.NewRecorder()}, http.StatusOK, []string{"asset1"})
+	if !strings.Contains(logged.String(), "failed to write response: connection reset by peer") {
+		t.Errorf("log = %q, want the failed write", logged.String())
+	}
+}
+
+func toJSON(v interface{}) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
@@ -0,0 +1,259 @@
+// Package readmodel keeps an off-chain read model of the assets for reporting.
+//
+// The chaincode answers questions about one asset cheaply, but a report over every asset
+// (the total value per owner, the assets of each color) would scan the whole world state in
+// a transaction. Instead a Projector applies the asset events (see package assetevents)
+// delivered by a listener.Listener to a Store, an embedded bbolt database, and the reports
+// are served from there, by the Store methods or over http by NewHandler.
+//
+// The read model is eventually consistent: it is as fresh as the last event the listener delivered.
+package readmodel
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/asset"
+)
+
+// Buckets of the database
+var (
+	// assetsBucket maps the ID of an asset to its json
+	assetsBucket = []byte("assets")
+	// ownersBucket and colorsBucket index the assets as owner\x00ID and color\x00ID keys
+	ownersBucket = []byte("owners")
+	colorsBucket = []byte("colors")
+	// transactionsBucket maps the ID of every applied transaction to its block number
+	transactionsBucket = []byte("transactions")
+)
+
+// ErrNotFound is returned for an asset that is not in the read model
+var ErrNotFound = errors.New("asset not found")
+
+// OwnerValue is the line of an owner in the value per owner report
+type OwnerValue struct {
+	Owner      string `json:"owner"`
+	Assets     int    `json:"assets"`
+	TotalValue int    `json:"totalValue"`
+}
+
+// ColorCount is the line of a color in the assets by color report
+type ColorCount struct {
+	Color  string `json:"color"`
+	Assets int    `json:"assets"`
+}
+
+// Store is the read model, a bbolt database holding the current state of every asset
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens the read model stored in the file path, creating it if needed.
+// bbolt locks the file, only one process can open it at a time.
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open read model: %v", err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{assetsBucket, ownersBucket, colorsBucket, transactionsBucket} {
+			_, err := tx.CreateBucketIfNotExists(bucket)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create read model buckets: %v", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Asset returns the asset assetID, or ErrNotFound. A soft deleted asset is returned with the status deleted.
+func (s *Store) Asset(assetID string) (*asset.Asset, error) {
+	var result *asset.Asset
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		result, err = getAsset(tx, assetID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, ErrNotFound
+	}
+	return result, nil
+}
+
+// AssetsByOwner returns the assets of owner (a display name), ordered by ID
+func (s *Store) AssetsByOwner(owner string) ([]*asset.Asset, error) {
+	return s.assetsByIndex(ownersBucket, owner)
+}
+
+// AssetsByColor returns the assets of color, ordered by ID
+func (s *Store) AssetsByColor(color string) ([]*asset.Asset, error) {
+	return s.assetsByIndex(colorsBucket, color)
+}
+
+// ValuePerOwner returns the number of assets and their total price for every owner, ordered by owner
+func (s *Store) ValuePerOwner() ([]*OwnerValue, error) {
+	values := make(map[string]*OwnerValue)
+	err := s.forEachAsset(func(a *asset.Asset) {
+		value, ok := values[a.Owner]
+		if !ok {
+			value = &OwnerValue{Owner: a.Owner}
+			values[a.Owner] = value
+		}
+		value.Assets++
+		value.TotalValue += a.Price
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := make([]*OwnerValue, 0, len(values))
+	for _, value := range values {
+		report = append(report, value)
+	}
+	sort.Slice(report, func(i, j int) bool { return report[i].Owner < report[j].Owner })
+	return report, nil
+}
+
+// AssetsPerColor returns the number of assets of every color, ordered by color
+func (s *Store) AssetsPerColor() ([]*ColorCount, error) {
+	counts := make(map[string]*ColorCount)
+	err := s.forEachAsset(func(a *asset.Asset) {
+		count, ok := counts[a.Color]
+		if !ok {
+			count = &ColorCount{Color: a.Color}
+			counts[a.Color] = count
+		}
+		count.Assets++
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := make([]*ColorCount, 0, len(counts))
+	for _, count := range counts {
+		report = append(report, count)
+	}
+	sort.Slice(report, func(i, j int) bool { return report[i].Color < report[j].Color })
+	return report, nil
+}
+
+// Applied tells if the events of the transaction txID were applied
+func (s *Store) Applied(txID string) (bool, error) {
+	applied := false
+	err := s.db.View(func(tx *bolt.Tx) error {
+		applied = tx.Bucket(transactionsBucket).Get([]byte(txID)) != nil
+		return nil
+	})
+	return applied, err
+}
+
+// assetsByIndex returns the assets whose indexed value is value, soft deleted assets are left out
+func (s *Store) assetsByIndex(bucket []byte, value string) ([]*asset.Asset, error) {
+	assets := []*asset.Asset{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		prefix := indexKey(value, "")
+		cursor := tx.Bucket(bucket).Cursor()
+		for key, _ := cursor.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, _ = cursor.Next() {
+			a, err := getAsset(tx, string(key[len(prefix):]))
+			if err != nil {
+				return err
+			}
+			if a != nil && !a.IsDeleted() {
+				assets = append(assets, a)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return assets, nil
+}
+
+// forEachAsset calls fn with every asset that is not soft deleted
+func (s *Store) forEachAsset(fn func(a *asset.Asset)) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(assetsBucket).ForEach(func(key, value []byte) error {
+			a, err := asset.Unmarshal(value)
+			if err != nil {
+				return err
+			}
+			if !a.IsDeleted() {
+				fn(a)
+			}
+			return nil
+		})
+	})
+}
+
+// getAsset reads assetID in tx, it returns nil if the asset is not in the read model
+func getAsset(tx *bolt.Tx, assetID string) (*asset.Asset, error) {
+	assetJSON := tx.Bucket(assetsBucket).Get([]byte(assetID))
+	if assetJSON == nil {
+		return nil, nil
+	}
+	return asset.Unmarshal(assetJSON)
+}
+
+// putAsset stores a in tx and moves it in the owner and color indexes
+func putAsset(tx *bolt.Tx, a *asset.Asset) error {
+	err := deleteAsset(tx, a.ID)
+	if err != nil {
+		return err
+	}
+	assetJSON, err := json.Marshal(a)
+	if err != nil {
+		return fmt.Errorf("failed to marshal asset %s: %v", a.ID, err)
+	}
+	err = tx.Bucket(assetsBucket).Put([]byte(a.ID), assetJSON)
+	if err != nil {
+		return err
+	}
+	err = tx.Bucket(ownersBucket).Put(indexKey(a.Owner, a.ID), []byte{})
+	if err != nil {
+		return err
+	}
+	return tx.Bucket(colorsBucket).Put(indexKey(a.Color, a.ID), []byte{})
+}
+
+// deleteAsset removes assetID and its index entries from tx, if it is there
+func deleteAsset(tx *bolt.Tx, assetID string) error {
+	existing, err := getAsset(tx, assetID)
+	if err != nil || existing == nil {
+		return err
+	}
+	err = tx.Bucket(ownersBucket).Delete(indexKey(existing.Owner, assetID))
+	if err != nil {
+		return err
+	}
+	err = tx.Bucket(colorsBucket).Delete(indexKey(existing.Color, assetID))
+	if err != nil {
+		return err
+	}
+	return tx.Bucket(assetsBucket).Delete([]byte(assetID))
+}
+
+// indexKey is the key of assetID in the index of value.
+// The 0 byte separates them, it can't appear in a display name or a color.
+func indexKey(value string, assetID string) []byte {
+	return []byte(value + "\x00" + assetID)
+}
@@ -0,0 +1,338 @@
+// Package webhook delivers chaincode events to other systems as signed http POST requests.
+//
+// A Dispatcher holds the subscriptions, each one an event name and the URL to POST its events to.
+// Registered with a listener.Listener, it delivers every event it subscribed to:
+//
+//	d := webhook.NewDispatcher("webhooks.deadletter")
+//	d.Subscribe(webhook.Subscription{EventName: assetevents.NameAssetTransferred, URL: "https://erp.example.com/hooks/asset", Secret: secret})
+//	d.Register(l)
+//	err := l.Run(ctx)
+//
+// The body of the request is a json Delivery. It is signed with the secret of the subscription
+// (see Sign and Verify), so the receiver can tell that it comes from us and was not altered.
+// A delivery that fails with a network error, a 5xx or a 429 is retried with exponential backoff.
+// When the retries run out, or the receiver rejects the delivery with another 4xx, the delivery
+// is appended to the dead letter file to be replayed by hand, and the dispatcher moves on.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/assetevents"
+	"github.com/salilOffice-cmd/GoPrac/offchain/listener"
+)
+
+// Subscription asks for the events named EventName to be POSTed to URL.
+// EventName is the name of a chaincode event, or of an asset event (AssetCreated, ...):
+// the asset events are delivered one by one, also when the chaincode emitted them in an AssetEvents envelope.
+type Subscription struct {
+	EventName string `json:"eventName"`
+	URL       string `json:"url"`
+	// Secret is the key of the HMAC signature of the requests, shared with the receiver
+	Secret string `json:"secret"`
+}
+
+// Delivery is the json body of a webhook request
+type Delivery struct {
+	// ID identifies the delivery of an event: a retried request keeps it, so the receiver can drop duplicates
+	ID          string          `json:"id"`
+	EventName   string          `json:"eventName"`
+	BlockNumber uint64          `json:"blockNumber"`
+	TxID        string          `json:"txId"`
+	ChaincodeID string          `json:"chaincodeId"`
+	Payload     json.RawMessage `json:"payload"`
+}
+
+// Backoff is the retry policy: the delay before retry n (from 1) is Initial * Multiplier^(n-1), at most Max
+type Backoff struct {
+	MaxAttempts int
+	Initial     time.Duration
+	Max         time.Duration
+	Multiplier  float64
+}
+
+// DefaultBackoff tries 5 times, waiting 1s, 2s, 4s and 8s in between
+var DefaultBackoff = Backoff{MaxAttempts: 5, Initial: time.Second, Max: time.Minute, Multiplier: 2}
+
+// Delay returns the delay before the retry n
+func (b Backoff) Delay(retry int) time.Duration {
+	delay := float64(b.Initial)
+	for i := 1; i < retry; i++ {
+		delay *= b.Multiplier
+		if delay >= float64(b.Max) {
+			return b.Max
+		}
+	}
+	return time.Duration(delay)
+}
+
+// Counters are the delivery metrics of an event name
+type Counters struct {
+	// Attempts counts the requests sent, Retries the ones after the first attempt of a delivery
+	Attempts     int `json:"attempts"`
+	Retries      int `json:"retries"`
+	Delivered    int `json:"delivered"`
+	DeadLettered int `json:"deadLettered"`
+	// LastError is the error of the last failed attempt
+	LastError string `json:"lastError,omitempty"`
+}
+
+// DeadLetter is a line of the dead letter file
+type DeadLetter struct {
+	URL       string    `json:"url"`
+	Delivery  *Delivery `json:"delivery"`
+	Attempts  int       `json:"attempts"`
+	LastError string    `json:"lastError"`
+	FailedAt  string    `json:"failedAt"`
+}
+
+// Dispatcher POSTs the events to the URLs that subscribed to them
+type Dispatcher struct {
+	subscriptions  map[string][]Subscription
+	deadLetterPath string
+
+	mu      sync.Mutex
+	metrics map[string]*Counters
+
+	// Client sends the requests, it defaults to a client with a 10s timeout
+	Client *http.Client
+	// Backoff is the retry policy, it defaults to DefaultBackoff
+	Backoff Backoff
+	// Now and Sleep are time.Now and a sleep that ends early when the context is done,
+	// tests replace them to run without waiting
+	Now   func() time.Time
+	Sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewDispatcher creates a Dispatcher without subscriptions that appends the failed deliveries to deadLetterPath
+func NewDispatcher(deadLetterPath string) *Dispatcher {
+	return &Dispatcher{
+		subscriptions:  make(map[string][]Subscription),
+		deadLetterPath: deadLetterPath,
+		metrics:        make(map[string]*Counters),
+		Client:         &http.Client{Timeout: 10 * time.Second},
+		Backoff:        DefaultBackoff,
+		Now:            time.Now,
+		Sleep:          sleep,
+	}
+}
+
+// Subscribe adds a subscription, an event name can have several
+func (d *Dispatcher) Subscribe(subscription Subscription) error {
+	if subscription.EventName == "" || subscription.URL == "" {
+		return fmt.Errorf("a subscription needs an event name and a URL")
+	}
+	d.subscriptions[subscription.EventName] = append(d.subscriptions[subscription.EventName], subscription)
+	return nil
+}
+
+// Register registers the dispatcher with l for every subscribed event name.
+// The events are delivered while the listener waits, so a slow receiver holds the listener back
+// for at most the backoff of one delivery.
+func (d *Dispatcher) Register(l *listener.Listener) {
+	for name := range d.subscriptions {
+		if isAssetEvent(l.Registry, name) {
+			l.HandleAsset(name, d.handleAsset)
+		} else {
+			l.Handle(name, d.handle)
+		}
+	}
+}
+
+// Metrics returns a copy of the delivery metrics of every event name
+func (d *Dispatcher) Metrics() map[string]Counters {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	metrics := make(map[string]Counters, len(d.metrics))
+	for name, counters := range d.metrics {
+		metrics[name] = *counters
+	}
+	return metrics
+}
+
+// handle delivers a chaincode event
+func (d *Dispatcher) handle(ctx context.Context, event *listener.Event) error {
+	payload := json.RawMessage(event.Payload)
+	if !json.Valid(payload) {
+		// the payload goes in a json document, send a payload that is not json as a string
+		payload, _ = json.Marshal(string(event.Payload))
+	}
+	return d.deliver(ctx, &Delivery{
+		ID:          event.TxID + "/" + event.EventName,
+		EventName:   event.EventName,
+		BlockNumber: event.BlockNumber,
+		TxID:        event.TxID,
+		ChaincodeID: event.ChaincodeID,
+		Payload:     payload,
+	})
+}
+
+// handleAsset delivers an asset event, alone even when it came in an envelope
+func (d *Dispatcher) handleAsset(ctx context.Context, event *listener.Event, assetEvent assetevents.Event) error {
+	header := assetEvent.EventHeader()
+	payload, err := json.Marshal(assetEvent)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %v", header.Name, err)
+	}
+	return d.deliver(ctx, &Delivery{
+		// an envelope carries an event per asset, the asset ID tells them apart
+		ID:          event.TxID + "/" + header.Name + "/" + header.AssetID,
+		EventName:   header.Name,
+		BlockNumber: event.BlockNumber,
+		TxID:        event.TxID,
+		ChaincodeID: event.ChaincodeID,
+		Payload:     payload,
+	})
+}
+
+// deliver POSTs the delivery to every subscription of its event name.
+// It only returns an error if the context is done or the dead letter can't be written,
+// the listener then stops and delivers the event again on its next run.
+func (d *Dispatcher) deliver(ctx context.Context, delivery *Delivery) error {
+	body, err := json.Marshal(delivery)
+	if err != nil {
+		return fmt.Errorf("failed to marshal delivery %s: %v", delivery.ID, err)
+	}
+
+	for _, subscription := range d.subscriptions[delivery.EventName] {
+		attempts, err := d.post(ctx, subscription, delivery, body)
+		if err == nil {
+			d.count(delivery.EventName, func(c *Counters) { c.Delivered++ })
+			continue
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		d.count(delivery.EventName, func(c *Counters) { c.DeadLettered++ })
+		err = d.writeDeadLetter(&DeadLetter{
+			URL:       subscription.URL,
+			Delivery:  delivery,
+			Attempts:  attempts,
+			LastError: err.Error(),
+			FailedAt:  d.Now().UTC().Format(time.RFC3339),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// post sends the delivery to the subscription until it succeeds, fails for good or runs out of attempts.
+// It returns the number of attempts and the error of the last one.
+func (d *Dispatcher) post(ctx context.Context, subscription Subscription, delivery *Delivery, body []byte) (int, error) {
+	attempt := 0
+	for {
+		attempt++
+		if attempt > 1 {
+			d.count(delivery.EventName, func(c *Counters) { c.Retries++ })
+		}
+		d.count(delivery.EventName, func(c *Counters) { c.Attempts++ })
+
+		retry, err := d.send(ctx, subscription, delivery, body)
+		if err == nil {
+			return attempt, nil
+		}
+		d.count(delivery.EventName, func(c *Counters) { c.LastError = err.Error() })
+		if !retry || attempt >= d.Backoff.MaxAttempts {
+			return attempt, err
+		}
+		if sleepErr := d.Sleep(ctx, d.Backoff.Delay(attempt)); sleepErr != nil {
+			return attempt, sleepErr
+		}
+	}
+}
+
+// send sends one request and tells if a failure is worth a retry
+func (d *Dispatcher) send(ctx context.Context, subscription Subscription, delivery *Delivery, body []byte) (bool, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %v", err)
+	}
+	timestamp := d.Now().Unix()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(HeaderEvent, delivery.EventName)
+	request.Header.Set(HeaderDelivery, delivery.ID)
+	request.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(HeaderSignature, Sign([]byte(subscription.Secret), timestamp, body))
+
+	response, err := d.Client.Do(request)
+	if err != nil {
+		return true, fmt.Errorf("failed to post to %s: %v", subscription.URL, err)
+	}
+	defer response.Body.Close()
+	// read the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64*1024))
+
+	switch {
+	case response.StatusCode >= 200 && response.StatusCode < 300:
+		return false, nil
+	case response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= 500:
+		return true, fmt.Errorf("%s answered %s", subscription.URL, response.Status)
+	default:
+		// the receiver refuses the delivery, sending it again won't change its mind
+		return false, fmt.Errorf("%s rejected the delivery: %s", subscription.URL, response.Status)
+	}
+}
+
+func (d *Dispatcher) count(eventName string, update func(c *Counters)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	counters, ok := d.metrics[eventName]
+	if !ok {
+		counters = new(Counters)
+		d.metrics[eventName] = counters
+	}
+	update(counters)
+}
+
+// writeDeadLetter appends a line to the dead letter file
+func (d *Dispatcher) writeDeadLetter(deadLetter *DeadLetter) error {
+	line, err := json.Marshal(deadLetter)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead letter: %v", err)
+	}
+	file, err := os.OpenFile(d.deadLetterPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open dead letter file: %v", err)
+	}
+	_, err = file.Write(append(line, '\n'))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write dead letter: %v", err)
+	}
+	return nil
+}
+
+// isAssetEvent tells if name is the name of an asset event of registry
+func isAssetEvent(registry *assetevents.Registry, name string) bool {
+	for _, assetEvent := range registry.Names() {
+		if assetEvent == name {
+			return true
+		}
+	}
+	return false
+}
+
+// sleep waits for d, or until the context is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,55 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers of a webhook request
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// signaturePrefix names the algorithm of the signature, so it can change without breaking receivers
+const signaturePrefix = "sha256="
+
+// Sign returns the signature of a webhook body sent at timestamp (unix seconds):
+// the hex HMAC-SHA256 with secret of "<timestamp>.<body>", prefixed with "sha256=".
+// The timestamp is signed too, so that a captured request can't be replayed later with a new timestamp.
+func Sign(secret []byte, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature and timestamp headers of a webhook request received at now.
+// It fails if the signature is not the one of body, or if the request is older than tolerance
+// (a tolerance of 0 accepts any age). Receivers call it before trusting the body:
+//
+//	err := webhook.Verify(secret, r.Header.Get(webhook.HeaderTimestamp), r.Header.Get(webhook.HeaderSignature), body, time.Now(), 5*time.Minute)
+func Verify(secret []byte, timestampHeader string, signature string, body []byte, now time.Time, tolerance time.Duration) error {
+	timestamp, err := strconv.ParseInt(timestampHeader, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid %s header %q", HeaderTimestamp, timestampHeader)
+	}
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return fmt.Errorf("invalid %s header, expecting a %s signature", HeaderSignature, strings.TrimSuffix(signaturePrefix, "="))
+	}
+	// hmac.Equal compares in constant time, so the signature can't be guessed byte by byte
+	if !hmac.Equal([]byte(signature), []byte(Sign(secret, timestamp, body))) {
+		return fmt.Errorf("signature does not match the body")
+	}
+	if age := now.Sub(time.Unix(timestamp, 0)); tolerance > 0 && (age > tolerance || age < -tolerance) {
+		return fmt.Errorf("request timestamp is %s away from now, more than %s", age.Round(time.Second), tolerance)
+	}
+	return nil
+}
@@ -0,0 +1,288 @@
+package webhook
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/assetevents"
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/chaincodetest"
+	"github.com/salilOffice-cmd/GoPrac/Chaincode/highlevel"
+	"github.com/salilOffice-cmd/GoPrac/offchain/listener"
+)
+
+const secret = "s3cret"
+
+var now = time.Date(2024, time.January, 31, 12, 0, 0, 0, time.UTC)
+
+// receiver is a webhook receiver that checks the signature of the requests
+// and answers with the statuses of its script, then 200
+type receiver struct {
+	mu        sync.Mutex
+	script    []int
+	received  []*Delivery
+	signature []error
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	body, _ := io.ReadAll(req.Body)
+	r.signature = append(r.signature, Verify([]byte(secret), req.Header.Get(HeaderTimestamp), req.Header.Get(HeaderSignature), body, now, time.Minute))
+
+	status := http.StatusOK
+	if len(r.script) > 0 {
+		status, r.script = r.script[0], r.script[1:]
+	}
+	if status == http.StatusOK {
+		delivery := new(Delivery)
+		json.Unmarshal(body, delivery)
+		r.received = append(r.received, delivery)
+	}
+	w.WriteHeader(status)
+}
+
+// newDispatcher returns a dispatcher that does not wait between retries but records the delays
+func newDispatcher(t *testing.T) (*Dispatcher, *[]time.Duration) {
+	t.Helper()
+	d := NewDispatcher(filepath.Join(t.TempDir(), "deadletter.jsonl"))
+	d.Backoff = Backoff{MaxAttempts: 3, Initial: time.Second, Max: 90 * time.Second, Multiplier: 2}
+	d.Now = func() time.Time { return now }
+	delays := new([]time.Duration)
+	d.Sleep = func(ctx context.Context, delay time.Duration) error {
+		*delays = append(*delays, delay)
+		return nil
+	}
+	return d, delays
+}
+
+func run(t *testing.T, d *Dispatcher, events ...*listener.Event) {
+	t.Helper()
+	l := listener.New(listener.NewSliceSource(events...), new(listener.MemoryCheckpointer))
+	d.Register(l)
+	if err := l.Run(context.Background()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+}
+
+// initLedgerEvent returns the AssetEvents envelope emitted by InitLedger
+func initLedgerEvent(t *testing.T) *listener.Event {
+	t.Helper()
+	chaincode, err := contractapi.NewChaincode(new(highlevel.SimpleAssetChaincode))
+	if err != nil {
+		t.Fatalf("failed to create chaincode: %v", err)
+	}
+	stub := chaincodetest.NewStub("asset", chaincode)
+	admin, err := chaincodetest.NewClientIdentity("Org1MSP", "admin", map[string]string{"role": "admin"})
+	if err != nil {
+		t.Fatalf("failed to create identity: %v", err)
+	}
+	if err := stub.SetCreator(admin); err != nil {
+		t.Fatalf("failed to set creator: %v", err)
+	}
+	if response := stub.MockInvoke("init", [][]byte{[]byte("InitLedger")}); response.Status != shim.OK {
+		t.Fatalf("InitLedger failed: %s", response.Message)
+	}
+	event, err := listener.NewChannelSource(stub.ChaincodeEventsChannel).Next(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return event
+}
+
+func TestDeliver(t *testing.T) {
+	assets := new(receiver)
+	pings := new(receiver)
+	assetServer := httptest.NewServer(assets)
+	defer assetServer.Close()
+	pingServer := httptest.NewServer(pings)
+	defer pingServer.Close()
+
+	d, _ := newDispatcher(t)
+	d.Subscribe(Subscription{EventName: assetevents.NameAssetCreated, URL: assetServer.URL, Secret: secret})
+	d.Subscribe(Subscription{EventName: "Ping", URL: pingServer.URL, Secret: secret})
+	run(t, d,
+		initLedgerEvent(t),
+		&listener.Event{BlockNumber: 2, TxID: "ping1", EventName: "Ping", Payload: []byte(`{"seq":1}`)},
+		&listener.Event{BlockNumber: 3, TxID: "ping2", EventName: "Ping", Payload: []byte("not json")},
+		&listener.Event{BlockNumber: 4, TxID: "other", EventName: "Other", Payload: []byte(`{}`)},
+	)
+
+	// the envelope of InitLedger is delivered as one AssetCreated per asset
+	var ids []string
+	for _, delivery := range assets.received {
+		ids = append(ids, delivery.ID)
+		event, err := assetevents.Decode(delivery.EventName, delivery.Payload)
+		if err != nil || event.(*assetevents.AssetCreated).After.ID != event.EventHeader().AssetID {
+			t.Errorf("delivery %s has payload %s, %v, want an AssetCreated event", delivery.ID, delivery.Payload, err)
+		}
+	}
+	if want := []string{"init/AssetCreated/asset1", "init/AssetCreated/asset2"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("asset deliveries = %v, want %v", ids, want)
+	}
+	if len(pings.received) != 2 || string(pings.received[0].Payload) != `{"seq":1}` || string(pings.received[1].Payload) != `"not json"` {
+		t.Errorf("ping deliveries = %+v, want the json payload and the text one as a string", pings.received)
+	}
+	for _, err := range append(assets.signature, pings.signature...) {
+		if err != nil {
+			t.Errorf("signature check failed: %v", err)
+		}
+	}
+
+	metrics := d.Metrics()
+	if want := (Counters{Attempts: 2, Delivered: 2}); metrics[assetevents.NameAssetCreated] != want {
+		t.Errorf("AssetCreated metrics = %+v, want %+v", metrics[assetevents.NameAssetCreated], want)
+	}
+	if _, ok := metrics["Other"]; ok {
+		t.Error("Other has metrics, want nothing delivered for an event nobody subscribed to")
+	}
+}
+
+func TestRetryAndDeadLetter(t *testing.T) {
+	flaky := &receiver{script: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}}
+	down := &receiver{script: []int{500, 502, 503}}
+	refusing := &receiver{script: []int{http.StatusBadRequest}}
+	var urls []string
+	for _, r := range []*receiver{flaky, down, refusing} {
+		server := httptest.NewServer(r)
+		defer server.Close()
+		urls = append(urls, server.URL)
+	}
+
+	d, delays := newDispatcher(t)
+	for _, url := range urls {
+		d.Subscribe(Subscription{EventName: "Ping", URL: url, Secret: secret})
+	}
+	run(t, d, &listener.Event{BlockNumber: 1, TxID: "tx1", EventName: "Ping", Payload: []byte(`{}`)})
+
+	if len(flaky.received) != 1 {
+		t.Errorf("flaky receiver got %d deliveries, want the third attempt delivered", len(flaky.received))
+	}
+	// two retries for the flaky receiver, two for the receiver that is down, none for the refusal
+	if want := []time.Duration{time.Second, 2 * time.Second, time.Second, 2 * time.Second}; !reflect.DeepEqual(*delays, want) {
+		t.Errorf("delays = %v, want %v", *delays, want)
+	}
+	want := Counters{Attempts: 7, Retries: 4, Delivered: 1, DeadLettered: 2, LastError: "rejected the delivery: 400 Bad Request"}
+	got := d.Metrics()["Ping"]
+	if !strings.Contains(got.LastError, want.LastError) {
+		t.Errorf("last error = %q, want it to contain %q", got.LastError, want.LastError)
+	}
+	got.LastError = want.LastError
+	if got != want {
+		t.Errorf("metrics = %+v, want %+v", got, want)
+	}
+
+	file, err := os.Open(d.deadLetterPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	var deadLetters []*DeadLetter
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		deadLetter := new(DeadLetter)
+		if err := json.Unmarshal(scanner.Bytes(), deadLetter); err != nil {
+			t.Fatal(err)
+		}
+		deadLetters = append(deadLetters, deadLetter)
+	}
+	if len(deadLetters) != 2 ||
+		deadLetters[0].URL != urls[1] || deadLetters[0].Attempts != 3 || !strings.Contains(deadLetters[0].LastError, "503") ||
+		deadLetters[1].URL != urls[2] || deadLetters[1].Attempts != 1 || deadLetters[1].Delivery.ID != "tx1/Ping" ||
+		deadLetters[1].FailedAt != "2024-01-31T12:00:00Z" {
+		t.Errorf("dead letters = %s, want the receiver that is down after 3 attempts and the refusal", toJSON(deadLetters))
+	}
+}
+
+func TestBackoffDelay(t *testing.T) {
+	b := Backoff{Initial: time.Second, Max: 5 * time.Second, Multiplier: 2}
+	var delays []time.Duration
+	for retry := 1; retry <= 5; retry++ {
+		delays = append(delays, b.Delay(retry))
+	}
+	if want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}; !reflect.DeepEqual(delays, want) {
+		t.Errorf("delays = %v, want %v", delays, want)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"id":"tx1/Ping"}`)
+	signature := Sign([]byte(secret), now.Unix(), body)
+	timestamp := "1706702400"
+
+	tests := []struct {
+		name        string
+		secret      string
+		timestamp   string
+		signature   string
+		body        string
+		wantMessage string
+	}{
+		{"valid", secret, timestamp, signature, string(body), ""},
+		{"other secret", "guess", timestamp, signature, string(body), "signature does not match"},
+		{"altered body", secret, timestamp, signature, `{"id":"tx2/Ping"}`, "signature does not match"},
+		{"other timestamp", secret, "1706702401", signature, string(body), "signature does not match"},
+		{"bad timestamp", secret, "noon", signature, string(body), "invalid X-Webhook-Timestamp header"},
+		{"other algorithm", secret, timestamp, "md5=abc", string(body), "expecting a sha256 signature"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify([]byte(tt.secret), tt.timestamp, tt.signature, []byte(tt.body), now, time.Minute)
+			if tt.wantMessage == "" {
+				if err != nil {
+					t.Errorf("Verify failed: %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantMessage) {
+				t.Errorf("Verify = %v, want an error containing %q", err, tt.wantMessage)
+			}
+		})
+	}
+
+	// a request replayed later is too old
+	err := Verify([]byte(secret), timestamp, signature, body, now.Add(time.Hour), time.Minute)
+	if err == nil || !strings.Contains(err.Error(), "more than 1m0s") {
+		t.Errorf("Verify an hour later = %v, want an error", err)
+	}
+}
+
+func TestDeliverCanceled(t *testing.T) {
+	down := &receiver{script: []int{500, 500, 500}}
+	server := httptest.NewServer(down)
+	defer server.Close()
+
+	d, _ := newDispatcher(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	d.Sleep = func(ctx context.Context, delay time.Duration) error {
+		cancel()
+		return ctx.Err()
+	}
+	d.Subscribe(Subscription{EventName: "Ping", URL: server.URL, Secret: secret})
+	l := listener.New(listener.NewSliceSource(&listener.Event{TxID: "tx1", EventName: "Ping", Payload: []byte(`{}`)}), new(listener.MemoryCheckpointer))
+	d.Register(l)
+
+	// the listener stops without a dead letter, the event is delivered again on its next run
+	if err := l.Run(ctx); err == nil || !strings.Contains(err.Error(), "context canceled") {
+		t.Errorf("Run = %v, want the context canceled", err)
+	}
+	if _, err := os.Stat(d.deadLetterPath); !os.IsNotExist(err) {
+		t.Errorf("dead letter file exists (%v), want none for a canceled delivery", err)
+	}
+}
+
+func toJSON(v interface{}) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}